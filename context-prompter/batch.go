@@ -0,0 +1,181 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+const (
+	defaultBatchItems   = 100
+	defaultBatchTokens  = 50000
+	defaultBatchWorkers = 4
+)
+
+// ContextInput is one item for AddContexts. An empty ID is replaced by ContentID.
+type ContextInput struct {
+	ID   string
+	Text string
+	Meta map[string]interface{}
+}
+
+// AddResult reports the outcome of one AddContexts item.
+type AddResult struct {
+	ID  string
+	Err error
+}
+
+// WithBatchSize caps the number of texts per embedding request.
+func WithBatchSize(n int) AddOption {
+	return func(c *addConfig) { c.batchItems = n }
+}
+
+// WithBatchTokens caps the estimated tokens per embedding request.
+func WithBatchTokens(n int) AddOption {
+	return func(c *addConfig) { c.batchTokens = n }
+}
+
+// WithWorkers sets how many embedding requests AddContexts runs concurrently.
+func WithWorkers(n int) AddOption {
+	return func(c *addConfig) { c.workers = n }
+}
+
+// AddContexts embeds and stores many items. Inputs are packed into embedding requests
+// bounded by item count and tokens, and batches run on a bounded worker pool. Results
+// are in input order with a per-item error; the returned error is non-nil if any item
+// failed. When the provider rejects a batch's input (llmproviders.IsInputError) its
+// items are retried one by one so a single bad input does not fail its neighbours;
+// other embedding errors fail the whole batch. Items the store rejects are reported
+// from its *vector.BatchError.
+func (p *Prompter) AddContexts(ctx context.Context, items []ContextInput, opts ...AddOption) ([]AddResult, error) {
+	embedder := p.embedder()
+	if embedder == nil || p.VectorDB == nil {
+		return nil, errEmbedderUnset
+	}
+	cfg := addConfig{batchItems: defaultBatchItems, batchTokens: defaultBatchTokens, workers: defaultBatchWorkers}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	results := make([]AddResult, len(items))
+	for i, it := range items {
+		results[i].ID = it.ID
+		if results[i].ID == "" {
+			results[i].ID = ContentID(cfg.namespace, it.Text, it.Meta)
+		}
+	}
+
+	batches := p.packBatches(items, cfg)
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	workers := cfg.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				p.addBatch(ctx, embedder, items, results, batch)
+			}
+		}()
+	}
+	for _, b := range batches {
+		if ctx.Err() != nil {
+			for _, i := range b {
+				results[i].Err = ctx.Err()
+			}
+			continue
+		}
+		jobs <- b
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d items failed", failed, len(items))
+	}
+	return results, nil
+}
+
+// packBatches groups item indexes into consecutive batches within the count and
+// token limits. An item larger than the token limit gets a batch of its own.
+func (p *Prompter) packBatches(items []ContextInput, cfg addConfig) [][]int {
+	tc := p.tokenCounter()
+	var batches [][]int
+	var cur []int
+	tokens := 0
+	for i, it := range items {
+		n := tc.CountTokens(it.Text)
+		if len(cur) > 0 && ((cfg.batchItems > 0 && len(cur) >= cfg.batchItems) || (cfg.batchTokens > 0 && tokens+n > cfg.batchTokens)) {
+			batches = append(batches, cur)
+			cur, tokens = nil, 0
+		}
+		cur = append(cur, i)
+		tokens += n
+	}
+	if len(cur) > 0 {
+		batches = append(batches, cur)
+	}
+	return batches
+}
+
+// addBatch embeds one batch and stores its items, recording errors in results.
+func (p *Prompter) addBatch(ctx context.Context, embedder llmproviders.Embedder, items []ContextInput, results []AddResult, batch []int) {
+	texts := make([]string, len(batch))
+	for j, i := range batch {
+		texts[j] = items[i].Text
+	}
+	vecs, err := embedder.EmbedBatch(ctx, texts)
+	if err == nil && len(vecs) != len(batch) {
+		err = fmt.Errorf("expected %d embeddings, got %d", len(batch), len(vecs))
+	}
+	if err != nil {
+		if len(batch) == 1 || !llmproviders.IsInputError(err) {
+			for _, i := range batch {
+				results[i].Err = err
+			}
+			return
+		}
+		// the provider rejected some input: retry one by one to isolate it
+		for _, i := range batch {
+			if ctx.Err() != nil {
+				results[i].Err = ctx.Err()
+				continue
+			}
+			p.addBatch(ctx, embedder, items, results, []int{i})
+		}
+		return
+	}
+	embs := make([]vector.Embedding, len(batch))
+	for j, i := range batch {
+		embs[j] = vector.Embedding{
+			ID:   results[i].ID,
+			Vec:  vecs[j],
+			Meta: withText(items[i].Meta, items[i].Text),
+		}
+	}
+	err = p.store().AddN(ctx, embs)
+	var be *vector.BatchError
+	switch {
+	case err == nil:
+	case errors.As(err, &be):
+		for j, ferr := range be.Failed {
+			results[batch[j]].Err = ferr
+		}
+	default:
+		for _, i := range batch {
+			results[i].Err = err
+		}
+	}
+}
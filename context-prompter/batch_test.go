@@ -0,0 +1,133 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db/local"
+)
+
+func inputs(texts ...string) []ContextInput {
+	out := make([]ContextInput, len(texts))
+	for i, t := range texts {
+		out[i] = ContextInput{ID: fmt.Sprintf("id%d", i), Text: t}
+	}
+	return out
+}
+
+func TestAddContextsOrder(t *testing.T) {
+	ctx := context.Background()
+	db := local.NewInMemoryVectorDB()
+	p := NewPrompterWithModels(&fakeEmbedder{}, nil, db, 0)
+	items := inputs("a", "bb", "ccc", "dddd", "eeeee", "ffffff", "g")
+	items[3].ID = "" // replaced by the content ID
+	res, err := p.AddContexts(ctx, items, WithBatchSize(2), WithWorkers(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(items) {
+		t.Fatalf("%d results for %d items", len(res), len(items))
+	}
+	for i, r := range res {
+		want := items[i].ID
+		if want == "" {
+			want = ContentID("", items[i].Text, nil)
+		}
+		if r.ID != want || r.Err != nil {
+			t.Errorf("result %d = %+v, want ID %s", i, r, want)
+		}
+	}
+	// each item is stored with its own vector
+	hits, _ := db.Search(ctx, []float64{1, 0}, 10)
+	for _, h := range hits {
+		if text := h.Meta[MetaText].(string); h.Vec[1] != float64(len(text)) {
+			t.Errorf("%s (%q) stored with %v", h.ID, text, h.Vec)
+		}
+	}
+	if n, _ := db.Count(ctx); n != len(items) {
+		t.Errorf("stored %d, want %d", n, len(items))
+	}
+}
+
+func TestAddContextsItemErrors(t *testing.T) {
+	ctx := context.Background()
+	outage := statusError(http.StatusServiceUnavailable)
+	tests := []struct {
+		name     string
+		embedder *fakeEmbedder
+		texts    []string
+		failed   map[int]error // nil value: any error
+		batches  int64
+	}{
+		{
+			name:     "rejected input is isolated",
+			embedder: &fakeEmbedder{fail: "bad"},
+			texts:    []string{"a", "bad", "c"},
+			failed:   map[int]error{1: statusError(http.StatusBadRequest)},
+			batches:  1 + 3,
+		},
+		{
+			name:     "outage fails the batch without retries",
+			embedder: &fakeEmbedder{fail: "bad", failErr: outage},
+			texts:    []string{"a", "bad", "c"},
+			failed:   map[int]error{0: outage, 1: outage, 2: outage},
+			batches:  1,
+		},
+		{
+			name:     "store rejects one item",
+			embedder: &fakeEmbedder{},
+			texts:    []string{"a", "empty", "c"},
+			failed:   map[int]error{1: nil},
+			batches:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := local.NewInMemoryVectorDB()
+			p := NewPrompterWithModels(tt.embedder, nil, db, 0)
+			res, err := p.AddContexts(ctx, inputs(tt.texts...), WithWorkers(1))
+			if err == nil {
+				t.Fatal("no error for a failed item")
+			}
+			for i, r := range res {
+				want, failed := tt.failed[i]
+				switch {
+				case !failed && r.Err != nil:
+					t.Errorf("item %d failed: %v", i, r.Err)
+				case failed && r.Err == nil:
+					t.Errorf("item %d succeeded", i)
+				case failed && want != nil && !errors.Is(r.Err, want):
+					t.Errorf("item %d: %v, want %v", i, r.Err, want)
+				}
+			}
+			if n, _ := db.Count(ctx); n != len(tt.texts)-len(tt.failed) {
+				t.Errorf("stored %d items", n)
+			}
+			if tt.embedder.batches != tt.batches {
+				t.Errorf("%d embedding calls, want %d", tt.embedder.batches, tt.batches)
+			}
+		})
+	}
+}
+
+func TestAddContextsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	emb := &fakeEmbedder{}
+	p := NewPrompterWithModels(emb, nil, local.NewInMemoryVectorDB(), 0)
+	res, err := p.AddContexts(ctx, inputs("a", "b", "c"), WithBatchSize(1))
+	if err == nil {
+		t.Fatal("cancelled AddContexts succeeded")
+	}
+	for i, r := range res {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("item %d: %v", i, r.Err)
+		}
+	}
+	if emb.batches != 0 {
+		t.Errorf("%d embedding calls after cancel", emb.batches)
+	}
+}
@@ -0,0 +1,328 @@
+package context_prompter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// ChunkStrategy selects how a document is split before embedding.
+type ChunkStrategy string
+
+const (
+	// ChunkFixed splits text into windows of Size runes, overlapping by Overlap runes.
+	ChunkFixed ChunkStrategy = "fixed"
+	// ChunkSentence groups whole sentences into chunks of at most Size runes.
+	ChunkSentence ChunkStrategy = "sentence"
+	// ChunkMarkdown splits on markdown headings; oversized sections fall back to fixed windows.
+	ChunkMarkdown ChunkStrategy = "markdown"
+)
+
+const (
+	defaultChunkSize    = 1000
+	defaultChunkOverlap = 200
+)
+
+// Metadata keys written on every stored chunk.
+const (
+	MetaText         = "text"
+	MetaParentID     = "parent_id"
+	MetaChunkIndex   = "chunk_index"
+	MetaChunkCount   = "chunk_count"
+	MetaChunkStart   = "chunk_start"
+	MetaChunkEnd     = "chunk_end"
+	MetaChunkHeading = "chunk_heading"
+)
+
+// ChunkOptions configures document splitting. Zero values fall back to defaults.
+type ChunkOptions struct {
+	Strategy ChunkStrategy
+	Size     int // max chunk length in runes
+	Overlap  int // runes (fixed) or trailing sentence runes (sentence) carried into the next chunk
+}
+
+// Chunk is a piece of a document. Start and End are byte offsets into the source text.
+type Chunk struct {
+	Index   int
+	Text    string
+	Start   int
+	End     int
+	Heading string // nearest markdown heading, if any
+}
+
+func (o ChunkOptions) withDefaults() ChunkOptions {
+	if o.Strategy == "" {
+		o.Strategy = ChunkFixed
+	}
+	if o.Size <= 0 {
+		o.Size = defaultChunkSize
+		if o.Overlap == 0 {
+			o.Overlap = defaultChunkOverlap
+		}
+	}
+	if o.Overlap < 0 {
+		o.Overlap = 0
+	}
+	if o.Overlap >= o.Size {
+		o.Overlap = o.Size / 5
+	}
+	return o
+}
+
+// SplitText splits text into chunks according to opts.
+func SplitText(text string, opts ChunkOptions) ([]Chunk, error) {
+	opts = opts.withDefaults()
+	var chunks []Chunk
+	switch opts.Strategy {
+	case ChunkFixed:
+		chunks = splitFixed(text, 0, opts.Size, opts.Overlap)
+	case ChunkSentence:
+		chunks = splitSentences(text, opts.Size, opts.Overlap)
+	case ChunkMarkdown:
+		chunks = splitMarkdown(text, opts.Size, opts.Overlap)
+	default:
+		return nil, fmt.Errorf("unknown chunk strategy: %s", opts.Strategy)
+	}
+	for i := range chunks {
+		chunks[i].Index = i
+	}
+	return chunks, nil
+}
+
+// AddDocument splits a long document into chunks, embeds each chunk and stores it
+// with its parent document ID, chunk index and byte offsets in Meta. It returns the
+// stored chunk IDs in order. Chunk IDs are "<docID>#<index>"; an empty docID is
+// replaced by the ContentID of the whole document. opts are passed to AddContexts.
+//
+// Re-adding a document overwrites its chunks by ID. If the new revision has fewer
+// chunks, the extra ones left from the previous revision are deleted when the
+// store implements vector.FilterDeleter; otherwise delete them yourself.
+func (p *Prompter) AddDocument(ctx context.Context, docID, text string, meta map[string]interface{}, chunking ChunkOptions, opts ...AddOption) ([]string, error) {
+	if docID == "" {
+		docID = ContentID("", text, meta)
+	}
+	chunks, err := SplitText(text, chunking)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]ContextInput, len(chunks))
+	for i, c := range chunks {
+		inputs[i] = ContextInput{
+			ID:   fmt.Sprintf("%s#%d", docID, c.Index),
+			Text: c.Text,
+			Meta: chunkMeta(meta, docID, c, len(chunks)),
+		}
+	}
+	results, err := p.AddContexts(ctx, inputs, opts...)
+	if err != nil {
+		for i, r := range results {
+			if r.Err != nil {
+				return nil, fmt.Errorf("chunk %d: %w", i, r.Err)
+			}
+		}
+		return nil, err
+	}
+	// the new chunks are stored, so dropping the stale tail never leaves a gap
+	if fd, ok := p.store().(vector.FilterDeleter); ok {
+		stale := vector.And(vector.Eq(MetaParentID, docID), vector.Gte(MetaChunkIndex, len(chunks)))
+		if _, err := fd.DeleteWhere(ctx, stale); err != nil {
+			return nil, fmt.Errorf("delete stale chunks: %w", err)
+		}
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids, nil
+}
+
+// chunkMeta copies the caller's metadata and adds the chunk bookkeeping fields.
+func chunkMeta(meta map[string]interface{}, docID string, c Chunk, count int) map[string]interface{} {
+	out := make(map[string]interface{}, len(meta)+7)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[MetaText] = c.Text
+	out[MetaParentID] = docID
+	out[MetaChunkIndex] = c.Index
+	out[MetaChunkCount] = count
+	out[MetaChunkStart] = c.Start
+	out[MetaChunkEnd] = c.End
+	if c.Heading != "" {
+		out[MetaChunkHeading] = c.Heading
+	}
+	return out
+}
+
+// splitFixed cuts text into rune windows. base is added to every offset so callers
+// can split a sub-slice of a larger document.
+func splitFixed(text string, base, size, overlap int) []Chunk {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	// byte offset of every rune boundary, plus len(text)
+	bounds := make([]int, 0, len(text)+1)
+	for i := range text {
+		bounds = append(bounds, i)
+	}
+	bounds = append(bounds, len(text))
+	runes := len(bounds) - 1
+
+	step := size - overlap
+	var chunks []Chunk
+	for start := 0; start < runes; start += step {
+		end := start + size
+		if end > runes {
+			end = runes
+		}
+		s, e := bounds[start], bounds[end]
+		chunks = append(chunks, Chunk{Text: text[s:e], Start: base + s, End: base + e})
+		if end == runes {
+			break
+		}
+	}
+	return chunks
+}
+
+type span struct{ start, end int }
+
+// sentenceSpans returns the byte spans of the sentences in text, whitespace trimmed.
+func sentenceSpans(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		if start < 0 {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			start = i
+		}
+		end := i + utf8.RuneLen(r)
+		switch {
+		case r == '\n' && end < len(text) && text[end] == '\n':
+			spans = append(spans, span{start, trimRight(text, start, i)})
+			start = -1
+		case r == '.' || r == '!' || r == '?':
+			if end == len(text) || isSpaceAt(text, end) {
+				spans = append(spans, span{start, end})
+				start = -1
+			}
+		}
+	}
+	if start >= 0 {
+		if e := trimRight(text, start, len(text)); e > start {
+			spans = append(spans, span{start, e})
+		}
+	}
+	return spans
+}
+
+func isSpaceAt(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r)
+}
+
+func trimRight(text string, start, end int) int {
+	return start + len(strings.TrimRightFunc(text[start:end], unicode.IsSpace))
+}
+
+// splitSentences packs whole sentences into chunks of at most size runes. A single
+// sentence longer than size is split with fixed windows.
+func splitSentences(text string, size, overlap int) []Chunk {
+	spans := sentenceSpans(text)
+	var chunks []Chunk
+	var cur []span
+	// rune length of text[from:end], including the whitespace between sentences that
+	// ends up in the chunk text
+	runesTo := func(from, end int) int {
+		return utf8.RuneCountInString(text[from:end])
+	}
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		s, e := cur[0].start, cur[len(cur)-1].end
+		chunks = append(chunks, Chunk{Text: text[s:e], Start: s, End: e})
+	}
+	for _, sp := range spans {
+		if runesTo(sp.start, sp.end) > size {
+			flush()
+			cur = nil
+			chunks = append(chunks, splitFixed(text[sp.start:sp.end], sp.start, size, overlap)...)
+			continue
+		}
+		if len(cur) > 0 && runesTo(cur[0].start, sp.end) > size {
+			flush()
+			// carry trailing sentences into the next chunk up to overlap runes
+			last := cur[len(cur)-1].end
+			i := len(cur)
+			for i > 0 && runesTo(cur[i-1].start, last) <= overlap && runesTo(cur[i-1].start, sp.end) <= size {
+				i--
+			}
+			cur = append([]span(nil), cur[i:]...)
+		}
+		cur = append(cur, sp)
+	}
+	flush()
+	return chunks
+}
+
+// splitMarkdown splits text into sections starting at ATX headings ("# ...").
+func splitMarkdown(text string, size, overlap int) []Chunk {
+	type section struct {
+		heading    string
+		start, end int
+	}
+	var sections []section
+	cur := section{}
+	offset := 0
+	inFence := false
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+		if !inFence && isHeading(trimmed) && offset > cur.start {
+			cur.end = offset
+			sections = append(sections, cur)
+			cur = section{start: offset}
+		}
+		if !inFence && isHeading(trimmed) {
+			cur.heading = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+		offset += len(line)
+	}
+	cur.end = len(text)
+	sections = append(sections, cur)
+
+	var chunks []Chunk
+	for _, s := range sections {
+		body := text[s.start:s.end]
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+		var parts []Chunk
+		if utf8.RuneCountInString(body) > size {
+			parts = splitFixed(body, s.start, size, overlap)
+		} else {
+			parts = []Chunk{{Text: body, Start: s.start, End: s.end}}
+		}
+		for i := range parts {
+			parts[i].Heading = s.heading
+		}
+		chunks = append(chunks, parts...)
+	}
+	return chunks
+}
+
+func isHeading(line string) bool {
+	n := 0
+	for n < len(line) && line[n] == '#' {
+		n++
+	}
+	return n >= 1 && n <= 6 && (len(line) == n || line[n] == ' ')
+}
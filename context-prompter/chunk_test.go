@@ -0,0 +1,207 @@
+package context_prompter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db/local"
+)
+
+// fakeEmbedder embeds a text as [1, its length], and "empty" as an empty vector.
+// A batch containing fail is rejected with failErr, a 400 input error by default.
+type fakeEmbedder struct {
+	batches int64 // EmbedBatch calls; first for atomic alignment
+	fail    string
+	failErr error
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	vecs, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func (e *fakeEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	atomic.AddInt64(&e.batches, 1)
+	vecs := make([][]float64, len(texts))
+	for i, t := range texts {
+		switch {
+		case e.fail != "" && t == e.fail:
+			if e.failErr != nil {
+				return nil, e.failErr
+			}
+			return nil, statusError(http.StatusBadRequest)
+		case t == "empty":
+			vecs[i] = []float64{}
+		default:
+			vecs[i] = []float64{1, float64(len(t))}
+		}
+	}
+	return vecs, nil
+}
+
+func (*fakeEmbedder) Dimensions() int { return 2 }
+
+// statusError is a provider error with an HTTP status.
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("HTTP %d", int(e)) }
+func (e statusError) HTTPStatus() int { return int(e) }
+
+// checkChunks verifies that every chunk is its source slice and fits size runes.
+func checkChunks(t *testing.T, text string, chunks []Chunk, size int) {
+	t.Helper()
+	for i, c := range chunks {
+		if c.Start < 0 || c.End > len(text) || c.Start >= c.End {
+			t.Fatalf("chunk %d: bad offsets [%d, %d)", i, c.Start, c.End)
+		}
+		if text[c.Start:c.End] != c.Text {
+			t.Errorf("chunk %d: text %q is not source[%d:%d] %q", i, c.Text, c.Start, c.End, text[c.Start:c.End])
+		}
+		if n := utf8.RuneCountInString(c.Text); n > size {
+			t.Errorf("chunk %d: %d runes, size %d", i, n, size)
+		}
+	}
+}
+
+func chunkTexts(chunks []Chunk) []string {
+	out := make([]string, len(chunks))
+	for i, c := range chunks {
+		out[i] = c.Text
+	}
+	return out
+}
+
+func TestSplitFixed(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		size, overlap int
+		want          []string
+	}{
+		{"overlap", "abcdefghij", 4, 1, []string{"abcd", "defg", "ghij"}},
+		{"no overlap", "abcdefghij", 4, 0, []string{"abcd", "efgh", "ij"}},
+		{"shorter than size", "abc", 10, 2, []string{"abc"}},
+		{"multibyte", "héllo wörld", 4, 1, []string{"héll", "lo w", "wörl", "ld"}},
+		{"blank", " \n\t ", 4, 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitFixed(tt.text, 0, tt.size, tt.overlap)
+			checkChunks(t, tt.text, got, tt.size)
+			if strings.Join(chunkTexts(got), "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", chunkTexts(got), tt.want)
+			}
+		})
+	}
+	// base shifts the offsets into the enclosing document
+	if c := splitFixed("xyz", 10, 2, 0); c[1].Start != 12 || c[1].End != 13 {
+		t.Errorf("base offsets = [%d, %d), want [12, 13)", c[1].Start, c[1].End)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	text := "One two. Three four five! Six?\n\nSeven eight nine ten eleven."
+	got := splitSentences(text, 26, 0)
+	checkChunks(t, text, got, 26)
+	// the last sentence alone is over the size, so it is cut into windows
+	want := []string{"One two. Three four five!", "Six?", "Seven eight nine ten eleve", "n."}
+	if strings.Join(chunkTexts(got), "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", chunkTexts(got), want)
+	}
+
+	// the previous sentence is carried into the next chunk
+	got = splitSentences("Aa. Bb. Cc. Dd.", 11, 3)
+	want = []string{"Aa. Bb. Cc.", "Cc. Dd."}
+	if strings.Join(chunkTexts(got), "|") != strings.Join(want, "|") {
+		t.Errorf("overlap: got %q, want %q", chunkTexts(got), want)
+	}
+
+	// whitespace between sentences counts against the size
+	text = "Aa." + strings.Repeat(" ", 20) + "Bb."
+	got = splitSentences(text, 10, 0)
+	checkChunks(t, text, got, 10)
+	if len(got) != 2 {
+		t.Errorf("padded sentences: got %q, want two chunks", chunkTexts(got))
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	text := "Intro line.\n# Setup\nInstall it.\n```sh\n# not a heading\nmake\n```\n## Usage\n" +
+		strings.Repeat("x", 80) + "\n"
+	got := splitMarkdown(text, 60, 5)
+	checkChunks(t, text, got, 60)
+	headings := make([]string, len(got))
+	for i, c := range got {
+		headings[i] = c.Heading
+	}
+	want := []string{"", "Setup", "Usage", "Usage"}
+	if strings.Join(headings, "|") != strings.Join(want, "|") {
+		t.Fatalf("headings = %q, want %q (chunks %q)", headings, want, chunkTexts(got))
+	}
+	// the fenced block stays in its section
+	if !strings.Contains(got[1].Text, "# not a heading") {
+		t.Errorf("fenced code split off: %q", got[1].Text)
+	}
+	// the oversized Usage section falls back to overlapping windows
+	if got[3].Start != got[2].End-5 {
+		t.Errorf("usage windows [%d, %d) and [%d, %d), want 5 runes of overlap", got[2].Start, got[2].End, got[3].Start, got[3].End)
+	}
+}
+
+func TestChunkOptionsDefaults(t *testing.T) {
+	tests := []struct {
+		in, want ChunkOptions
+	}{
+		{ChunkOptions{}, ChunkOptions{Strategy: ChunkFixed, Size: defaultChunkSize, Overlap: defaultChunkOverlap}},
+		{ChunkOptions{Overlap: 50}, ChunkOptions{Strategy: ChunkFixed, Size: defaultChunkSize, Overlap: 50}},
+		{ChunkOptions{Size: 100}, ChunkOptions{Strategy: ChunkFixed, Size: 100}},
+		{ChunkOptions{Size: 100, Overlap: -1}, ChunkOptions{Strategy: ChunkFixed, Size: 100}},
+		{ChunkOptions{Size: 100, Overlap: 100}, ChunkOptions{Strategy: ChunkFixed, Size: 100, Overlap: 20}},
+	}
+	for _, tt := range tests {
+		if got := tt.in.withDefaults(); got != tt.want {
+			t.Errorf("%+v: got %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := SplitText("x", ChunkOptions{Strategy: "paragraph"}); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
+
+func TestAddDocumentDropsStaleChunks(t *testing.T) {
+	ctx := context.Background()
+	db := local.NewInMemoryVectorDB()
+	p := NewPrompterWithModels(&fakeEmbedder{}, nil, db, 0)
+	opts := ChunkOptions{Size: 4}
+	if _, err := p.AddDocument(ctx, "doc", "aaaabbbbccccdddd", nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Add(ctx, vector.Embedding{ID: "other#3", Vec: []float64{1, 1},
+		Meta: map[string]interface{}{MetaParentID: "other", MetaChunkIndex: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := p.AddDocument(ctx, "doc", "eeeeffff", nil, opts, WithWorkers(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "doc#0,doc#1" {
+		t.Errorf("ids = %v", ids)
+	}
+	res, _ := db.Search(ctx, []float64{1, 0}, 10)
+	var got []string
+	for _, r := range res {
+		got = append(got, r.ID)
+	}
+	if n, _ := db.Count(ctx); n != 3 {
+		t.Errorf("store holds %v, want doc#0, doc#1 and other#3", got)
+	}
+}
@@ -0,0 +1,88 @@
+package context_prompter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Meta keys read as a citation's source, in order of preference.
+const (
+	MetaURL    = "url"
+	MetaSource = "source"
+)
+
+// Citation is a source the answer cited, resolved to the context item sent as [n].
+type Citation struct {
+	Number   int    // n as written in the answer
+	ID       string // embedding ID of the item
+	Source   string // Meta["url"], else Meta["source"]
+	ParentID string // document the chunk came from, if chunked
+	Start    int    // byte offsets of the chunk in its document, -1 when unknown
+	End      int
+	Meta     map[string]interface{}
+}
+
+// matches [1], [2, 3] and [4][5]
+var citationRe = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// WithCitations numbers the context, asks the model to cite it and resolves the [n]
+// markers of the answer into QueryResult.Citations. It uses the "cited" template
+// unless a custom one is set, which must then number items and ask for [n] citations
+// itself; Query fails if it does not render {{.Number}}.
+func WithCitations() QueryOption {
+	return func(c *queryConfig) { c.citations = true }
+}
+
+// ResolveCitations parses the [n] markers in answer and fills Citations, in order of
+// first use, and InvalidCitations with numbers that match no item sent. QueryDetailed
+// calls it when WithCitations is set; call it on the final text of QueryStream.
+func (r *QueryResult) ResolveCitations(answer string) {
+	r.Citations, r.InvalidCitations = nil, nil
+	seen := map[int]bool{}
+	for _, m := range citationRe.FindAllStringSubmatch(answer, -1) {
+		for _, part := range strings.Split(m[1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || seen[n] {
+				continue
+			}
+			seen[n] = true
+			if n < 1 || n > len(r.Included) {
+				r.InvalidCitations = append(r.InvalidCitations, n)
+				continue
+			}
+			r.Citations = append(r.Citations, newCitation(n, r.Included[n-1]))
+		}
+	}
+}
+
+func newCitation(n int, it ContextItem) Citation {
+	meta := it.Embedding.Meta
+	c := Citation{Number: n, ID: it.Embedding.ID, Start: -1, End: -1, Meta: meta}
+	for _, key := range []string{MetaURL, MetaSource} {
+		if s, ok := meta[key].(string); ok && s != "" {
+			c.Source = s
+			break
+		}
+	}
+	c.ParentID, _ = meta[MetaParentID].(string)
+	if start, ok := metaInt(meta, MetaChunkStart); ok {
+		if end, ok := metaInt(meta, MetaChunkEnd); ok {
+			c.Start, c.End = start, end
+		}
+	}
+	return c
+}
+
+// metaInt reads an integer stored directly or decoded from JSON as a float64.
+func metaInt(meta map[string]interface{}, key string) (int, bool) {
+	switch v := meta[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,93 @@
+package context_prompter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+func TestResolveCitations(t *testing.T) {
+	tests := []struct {
+		answer  string
+		cited   []int
+		invalid []int
+	}{
+		{"Refunds take 5 days [1].", []int{1}, nil},
+		{"Both apply [2, 3].", []int{2, 3}, nil},
+		{"See [3][1] and again [1].", []int{3, 1}, nil},
+		{"Per [4], [0] and [2,9].", []int{2}, []int{4, 0, 9}},
+		{"No markers, just [x] and [ ].", nil, nil},
+	}
+	for _, tt := range tests {
+		res := &QueryResult{Included: make([]ContextItem, 3)}
+		res.ResolveCitations(tt.answer)
+		var cited []int
+		for _, c := range res.Citations {
+			cited = append(cited, c.Number)
+		}
+		if !reflect.DeepEqual(cited, tt.cited) || !reflect.DeepEqual(res.InvalidCitations, tt.invalid) {
+			t.Errorf("%q: cited %v invalid %v, want %v and %v", tt.answer, cited, res.InvalidCitations, tt.cited, tt.invalid)
+		}
+	}
+}
+
+func TestCitationSource(t *testing.T) {
+	meta := map[string]interface{}{
+		MetaSource: "handbook.pdf", MetaParentID: "doc", MetaChunkStart: 120, MetaChunkEnd: 240,
+	}
+	// stores that keep Meta as JSON hand the offsets back as float64
+	raw, _ := json.Marshal(meta)
+	var decoded map[string]interface{}
+	json.Unmarshal(raw, &decoded)
+	for _, m := range []map[string]interface{}{meta, decoded} {
+		res := &QueryResult{Included: []ContextItem{{Embedding: vector.Embedding{ID: "x", Meta: m}}}}
+		res.ResolveCitations("[1]")
+		c := res.Citations[0]
+		if c.ID != "x" || c.Source != "handbook.pdf" || c.ParentID != "doc" || c.Start != 120 || c.End != 240 {
+			t.Errorf("%T offsets: got %+v", m[MetaChunkStart], c)
+		}
+	}
+
+	// a URL is preferred over a source name; missing offsets are -1
+	res := &QueryResult{Included: []ContextItem{{Embedding: vector.Embedding{
+		Meta: map[string]interface{}{MetaURL: "https://example.com/a", MetaSource: "a", MetaChunkStart: 3},
+	}}}}
+	res.ResolveCitations("[1]")
+	if c := res.Citations[0]; c.Source != "https://example.com/a" || c.Start != -1 || c.End != -1 {
+		t.Errorf("got %+v", c)
+	}
+}
+
+func TestQueryWithCitations(t *testing.T) {
+	ctx := context.Background()
+	gen := &fakeGenerator{reply: func(prompt string, contextItems []string) (string, error) {
+		if !strings.Contains(contextItems[0], "[2] ") {
+			return "", fmt.Errorf("context not numbered: %q", contextItems)
+		}
+		return "It is [2] and [7].", nil
+	}}
+	p, _ := hybridPrompter(t)
+	p.Generator = gen
+	res, err := p.QueryDetailed(ctx, "ERR_42", 2, WithCitations())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Citations) != 1 || res.Citations[0].ID != res.Included[1].Embedding.ID || !reflect.DeepEqual(res.InvalidCitations, []int{7}) {
+		t.Errorf("citations %+v, invalid %v", res.Citations, res.InvalidCitations)
+	}
+
+	// a custom template has to number the items itself
+	p.Template = &PromptTemplate{System: "{{.Context}}", Item: "{{.Text}}", Question: "{{.Question}}"}
+	if _, err := p.QueryDetailed(ctx, "ERR_42", 2, WithCitations()); err == nil || !strings.Contains(err.Error(), "{{.Number}}") {
+		t.Errorf("unnumbered template: err = %v", err)
+	}
+	p.Template = &PromptTemplate{System: "{{.Context}}", Item: "[{{.Number}}] {{.Text}}", Question: "{{.Question}}"}
+	if _, err := p.QueryDetailed(ctx, "ERR_42", 2, WithCitations()); err != nil {
+		t.Errorf("numbered template rejected: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// SearchMode selects how Query retrieves context.
+type SearchMode int
+
+const (
+	SearchVector  SearchMode = iota // embedding similarity only (default)
+	SearchKeyword                   // keyword relevance only, no embedding call
+	SearchHybrid                    // both, merged with a vector.Fusion
+)
+
+// each leg of a hybrid search fetches this many times topK before fusion
+const hybridFetchFactor = 2
+
+var errNoTextSearch = errors.New("VectorDB does not support keyword search")
+
+// WithSearchMode sets the retrieval mode for Query.
+func WithSearchMode(m SearchMode) QueryOption {
+	return func(c *queryConfig) { c.mode = m }
+}
+
+// WithFusion sets how SearchHybrid merges the two rankings, vector.RRF(60) by default.
+func WithFusion(f vector.Fusion) QueryOption {
+	return func(c *queryConfig) { c.fusion = f }
+}
+
+// KeywordContext returns the top K context items by keyword relevance. VectorDB must
+// implement vector.TextSearcher.
+func (p *Prompter) KeywordContext(ctx context.Context, query string, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	if p.VectorDB == nil {
+		return nil, errors.New("VectorDB must be set")
+	}
+	ts, ok := p.store().(vector.TextSearcher)
+	if !ok {
+		return nil, errNoTextSearch
+	}
+	return ts.TextSearch(ctx, query, topK, opts...)
+}
+
+// HybridContext runs vector and keyword search and merges them with fusion
+// (vector.RRF(60) when nil), so exact identifiers such as error codes or SKUs are
+// found even when their embeddings are not close. MinScore only applies to the
+// vector leg.
+func (p *Prompter) HybridContext(ctx context.Context, query string, topK int, fusion vector.Fusion, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	return p.hybrid(ctx, query, nil, topK, fusion, opts)
+}
+
+// hybrid is HybridContext reusing queryVec when the caller already embedded query.
+func (p *Prompter) hybrid(ctx context.Context, query string, queryVec []float64, topK int, fusion vector.Fusion, opts []vector.SearchOption) ([]vector.SearchResult, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+	if fusion == nil {
+		fusion = vector.RRF(0)
+	}
+	fetch := topK * hybridFetchFactor
+	vec, err := p.vectorSearch(ctx, query, queryVec, fetch, opts)
+	if err != nil {
+		return nil, err
+	}
+	text, err := p.KeywordContext(ctx, query, fetch, opts...)
+	if err != nil {
+		return nil, err
+	}
+	fused := fusion(vec, text)
+	if len(fused) > topK {
+		fused = fused[:topK]
+	}
+	return fused, nil
+}
+
+// vectorSearch is SimilarContext, skipping the embedding call when queryVec is set.
+func (p *Prompter) vectorSearch(ctx context.Context, query string, queryVec []float64, topK int, opts []vector.SearchOption) ([]vector.SearchResult, error) {
+	if queryVec == nil {
+		return p.SimilarContext(ctx, query, topK, opts...)
+	}
+	return p.store().Search(ctx, queryVec, topK, opts...)
+}
+
+// retrieve fetches ranked context for query in the configured search mode. queryVec
+// is the query's embedding if already known, or nil.
+func (p *Prompter) retrieve(ctx context.Context, query string, queryVec []float64, topK int, cfg queryConfig) ([]vector.SearchResult, error) {
+	switch cfg.mode {
+	case SearchKeyword:
+		return p.KeywordContext(ctx, query, topK, cfg.search...)
+	case SearchHybrid:
+		return p.hybrid(ctx, query, queryVec, topK, cfg.fusion, cfg.search)
+	default:
+		return p.vectorSearch(ctx, query, queryVec, topK, cfg.search)
+	}
+}
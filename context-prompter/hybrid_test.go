@@ -0,0 +1,98 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db/local"
+)
+
+// hybridPrompter stores texts whose fake embeddings rank the short decoys above the
+// document naming the error code.
+func hybridPrompter(t *testing.T) (*Prompter, *fakeEmbedder) {
+	t.Helper()
+	emb := &fakeEmbedder{}
+	p := NewPrompterWithModels(emb, &fakeGenerator{}, local.NewInMemoryVectorDB(), 0)
+	_, err := p.AddContexts(context.Background(), []ContextInput{
+		{ID: "code", Text: "ERR_42 means the disk is full"},
+		{ID: "decoy1", Text: "abcdef"},
+		{ID: "decoy2", Text: "ghijkl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, emb
+}
+
+func resultIDs(res []vector.SearchResult) []string {
+	out := make([]string, len(res))
+	for i, r := range res {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestQuerySearchModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []llmproviders.PromptOption
+		want   []string
+		embeds bool
+	}{
+		{"vector misses the identifier", nil, []string{"decoy1", "decoy2"}, true},
+		{"keyword", []llmproviders.PromptOption{WithSearchMode(SearchKeyword)}, []string{"code"}, false},
+		// RRF puts the one result both legs found first
+		{"hybrid", []llmproviders.PromptOption{WithSearchMode(SearchHybrid)}, []string{"code", "decoy1"}, true},
+		{"hybrid weighted to vectors", []llmproviders.PromptOption{WithSearchMode(SearchHybrid), WithFusion(vector.Weighted(1))}, []string{"decoy1", "decoy2"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, emb := hybridPrompter(t)
+			before := atomic.LoadInt64(&emb.batches)
+			res, err := p.QueryDetailed(context.Background(), "ERR_42", 2, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, it := range res.Included {
+				got = append(got, it.Embedding.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("context %v, want %v", got, tt.want)
+			}
+			if embedded := atomic.LoadInt64(&emb.batches) > before; embedded != tt.embeds {
+				t.Errorf("query embedded = %v, want %v", embedded, tt.embeds)
+			}
+		})
+	}
+}
+
+func TestKeywordAndHybridContext(t *testing.T) {
+	ctx := context.Background()
+	p, _ := hybridPrompter(t)
+	res, err := p.KeywordContext(ctx, "disk", 5)
+	if err != nil || len(res) != 1 || res[0].ID != "code" || res[0].Metric != vector.BM25 {
+		t.Errorf("KeywordContext = %v, %v", resultIDs(res), err)
+	}
+	res, err = p.HybridContext(ctx, "ERR_42", 1, nil)
+	if err != nil || len(res) != 1 || res[0].Metric != vector.Fused {
+		t.Errorf("HybridContext = %v, %v; want one fused result", res, err)
+	}
+	if res, err := p.HybridContext(ctx, "ERR_42", 0, nil); err != nil || res != nil {
+		t.Errorf("topK 0 = %v, %v", res, err)
+	}
+
+	// a store without keyword search is reported, not treated as no results
+	p.VectorDB = struct{ vector.VectorDB }{p.VectorDB}
+	if _, err := p.KeywordContext(ctx, "disk", 5); !errors.Is(err, errNoTextSearch) {
+		t.Errorf("KeywordContext err = %v", err)
+	}
+	if _, err := p.HybridContext(ctx, "disk", 5, nil); !errors.Is(err, errNoTextSearch) {
+		t.Errorf("HybridContext err = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package context_prompter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// AddOption customises how AddContext stores an item.
+type AddOption func(*addConfig)
+
+type addConfig struct {
+	id        string
+	namespace string
+
+	// AddContexts batching
+	batchItems  int
+	batchTokens int
+	workers     int
+}
+
+// WithID stores the item under a caller-chosen ID instead of a content hash.
+// AddContexts ignores it; set ContextInput.ID instead.
+func WithID(id string) AddOption {
+	return func(c *addConfig) { c.id = id }
+}
+
+// WithIDNamespace prefixes the generated content ID so different callers storing the
+// same text do not collide.
+func WithIDNamespace(ns string) AddOption {
+	return func(c *addConfig) { c.namespace = ns }
+}
+
+// ContentID returns a deterministic ID for text and its metadata. The same text with
+// different metadata yields different IDs. A non-empty namespace is prepended as "ns:".
+func ContentID(namespace, text string, meta map[string]interface{}) string {
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	h.Write([]byte{0})
+	if len(meta) > 0 {
+		// encoding/json sorts map keys, so this is stable
+		b, _ := json.Marshal(meta)
+		h.Write(b)
+	}
+	id := hex.EncodeToString(h.Sum(nil)[:16])
+	if namespace != "" {
+		return namespace + ":" + id
+	}
+	return id
+}
+
+// withText returns a copy of meta carrying text under MetaText, so items stay readable
+// now that the ID is no longer the text itself.
+func withText(meta map[string]interface{}, text string) map[string]interface{} {
+	out := make(map[string]interface{}, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	if _, ok := out[MetaText]; !ok {
+		out[MetaText] = text
+	}
+	return out
+}
@@ -0,0 +1,20 @@
+package context_prompter
+
+// candidates fetched per final item when reranking or diversifying without an
+// explicit pool size
+const defaultCandidateFactor = 4
+
+// WithMMR selects the topK items by Maximal Marginal Relevance from a pool of
+// candidates (4*topK when candidates <= 0), so near-duplicate chunks do not fill the
+// context window. lambda 1 is plain relevance; lower values favour diversity, 0.5 to
+// 0.7 is a good start. It uses the vectors the store returns with each result, so it
+// works on every backend. With a reranker, MMR runs on the reranked pool and takes
+// relevance from the reranker's scores.
+func WithMMR(lambda float64, candidates int) QueryOption {
+	return func(c *queryConfig) {
+		c.mmrLambda = &lambda
+		if candidates > 0 {
+			c.candidates = candidates
+		}
+	}
+}
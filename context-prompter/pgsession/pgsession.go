@@ -0,0 +1,85 @@
+// Package pgsession stores chat sessions of context_prompter in Postgres, so
+// conversations survive restarts and can move between instances. Session.Ask
+// reloads the row before every turn; concurrent turns on one session are not
+// merged, the last save wins.
+package pgsession
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	context_prompter "github.com/shreetheja/ai-contextual-prompter/context-prompter"
+)
+
+// DefaultTable is the table used when NewStore is given an empty name.
+const DefaultTable = "chat_sessions"
+
+// Store is a context_prompter.SessionStore with one row per session.
+type Store struct {
+	db    *pgxpool.Pool
+	table string // sanitized, possibly schema-qualified
+}
+
+var _ context_prompter.SessionStore = (*Store)(nil)
+
+// NewStore returns a Store on table ("schema.table" is accepted), creating the
+// table if it does not exist.
+func NewStore(ctx context.Context, pool *pgxpool.Pool, table string) (*Store, error) {
+	if table == "" {
+		table = DefaultTable
+	}
+	s := &Store{db: pool, table: pgx.Identifier(strings.Split(table, ".")).Sanitize()}
+	_, err := pool.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id         text PRIMARY KEY,
+	summary    text NOT NULL DEFAULT '',
+	messages   jsonb NOT NULL DEFAULT '[]',
+	updated_at timestamptz NOT NULL DEFAULT now()
+)`, s.table))
+	if err != nil {
+		return nil, fmt.Errorf("pgsession: create %s: %w", s.table, err)
+	}
+	return s, nil
+}
+
+func (s *Store) Load(ctx context.Context, id string) (*context_prompter.SessionState, error) {
+	st := &context_prompter.SessionState{ID: id}
+	var raw []byte
+	err := s.db.QueryRow(ctx, fmt.Sprintf(`SELECT summary, messages FROM %s WHERE id = $1`, s.table), id).
+		Scan(&st.Summary, &raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, context_prompter.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &st.Messages); err != nil {
+		return nil, fmt.Errorf("pgsession: decode messages of %q: %w", id, err)
+	}
+	return st, nil
+}
+
+func (s *Store) Save(ctx context.Context, state *context_prompter.SessionState) error {
+	msgs := state.Messages
+	if msgs == nil {
+		msgs = []context_prompter.Message{}
+	}
+	raw, err := json.Marshal(msgs)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, fmt.Sprintf(`INSERT INTO %s (id, summary, messages, updated_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (id) DO UPDATE SET summary = EXCLUDED.summary, messages = EXCLUDED.messages, updated_at = now()`, s.table),
+		state.ID, state.Summary, raw)
+	return err
+}
+
+func (s *Store) Delete(ctx context.Context, id string) error {
+	_, err := s.db.Exec(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, s.table), id)
+	return err
+}
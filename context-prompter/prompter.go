@@ -3,19 +3,35 @@ package context_prompter
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
 	"github.com/shreetheja/ai-contextual-prompter/vector-db"
 )
 
 // Prompter manages context and LLM for contextual prompting.
+//
+// Embedding and generation can come from different vendors: set Embedder and
+// Generator separately, or set LLM to use one provider for both. Embedder and
+// Generator take precedence over LLM when set.
+//
+// Namespace scopes every read and write, including ClearContext, to one tenant's
+// partition of VectorDB.
 type Prompter struct {
 	VectorDB   vector.VectorDB
 	LLM        llmproviders.LLM
-	MaxContext int // max context items/tokens to use in prompt
+	Embedder   llmproviders.Embedder
+	Generator  llmproviders.Generator
+	MaxContext int             // max context items to use in prompt, 0 for no limit
+	Tokens     TokenCounter    // counts prompt tokens; ApproxTokenCounter when nil
+	Namespace  string          // VectorDB namespace, "" for the default one
+	Template   *PromptTemplate // how context and question are worded; the "default" built-in when nil
 }
 
+var (
+	errEmbedderUnset  = errors.New("Embedder (or LLM) and VectorDB must be set")
+	errGeneratorUnset = errors.New("Generator (or LLM) must be set")
+)
+
 // NewPrompter returns an empty Prompter with MaxContext set.
 func NewPrompter(maxContext int) *Prompter {
 	return &Prompter{MaxContext: maxContext}
@@ -26,6 +42,11 @@ func NewPrompterWithLLM(llm llmproviders.LLM, maxContext int) *Prompter {
 	return &Prompter{LLM: llm, MaxContext: maxContext}
 }
 
+// NewPrompterWithModels returns a Prompter that embeds with emb and generates with gen.
+func NewPrompterWithModels(emb llmproviders.Embedder, gen llmproviders.Generator, vdb vector.VectorDB, maxContext int) *Prompter {
+	return &Prompter{Embedder: emb, Generator: gen, VectorDB: vdb, MaxContext: maxContext}
+}
+
 // NewPrompterWithVector returns a Prompter with VectorDB and MaxContext set.
 func NewPrompterWithVector(vdb vector.VectorDB, maxContext int) *Prompter {
 	return &Prompter{VectorDB: vdb, MaxContext: maxContext}
@@ -36,62 +57,238 @@ func (p *Prompter) SetLLM(llm llmproviders.LLM) {
 	p.LLM = llm
 }
 
+// SetEmbedder sets the embedding provider, overriding LLM for embeddings.
+func (p *Prompter) SetEmbedder(emb llmproviders.Embedder) {
+	p.Embedder = emb
+}
+
+// SetGenerator sets the generation provider, overriding LLM for answers.
+func (p *Prompter) SetGenerator(gen llmproviders.Generator) {
+	p.Generator = gen
+}
+
+// embedder returns Embedder, or the embedding side of LLM.
+func (p *Prompter) embedder() llmproviders.Embedder {
+	if p.Embedder != nil {
+		return p.Embedder
+	}
+	if p.LLM != nil {
+		return llmproviders.EmbedderFromLLM(p.LLM)
+	}
+	return nil
+}
+
+// generator returns Generator, or LLM.
+func (p *Prompter) generator() llmproviders.Generator {
+	if p.Generator != nil {
+		return p.Generator
+	}
+	if p.LLM != nil {
+		return p.LLM
+	}
+	return nil
+}
+
 // SetVector sets the VectorDB for the Prompter.
 func (p *Prompter) SetVector(vdb vector.VectorDB) {
 	p.VectorDB = vdb
 }
 
-// AddContext adds a new context item (text + metadata) and stores its embedding.
-func (p *Prompter) AddContext(ctx context.Context, text string, meta map[string]interface{}) error {
-	if p.LLM == nil || p.VectorDB == nil {
-		return errors.New("LLM and VectorDB must be set")
+// WithNamespace returns a copy of the Prompter bound to namespace ns. The copy shares
+// the providers and the VectorDB.
+func (p *Prompter) WithNamespace(ns string) *Prompter {
+	c := *p
+	c.Namespace = ns
+	return &c
+}
+
+// store returns VectorDB scoped to Namespace.
+func (p *Prompter) store() vector.VectorDB {
+	if p.VectorDB == nil || p.Namespace == "" {
+		return p.VectorDB
 	}
-	embedding, err := p.LLM.Embed(ctx, text)
+	return p.VectorDB.WithNamespace(p.Namespace)
+}
+
+// AddContext adds a new context item (text + metadata), stores its embedding and
+// returns its ID. Unless WithID is given, the ID is a content hash of text and meta.
+func (p *Prompter) AddContext(ctx context.Context, text string, meta map[string]interface{}, opts ...AddOption) (string, error) {
+	embedder := p.embedder()
+	if embedder == nil || p.VectorDB == nil {
+		return "", errEmbedderUnset
+	}
+	var cfg addConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	id := cfg.id
+	if id == "" {
+		id = ContentID(cfg.namespace, text, meta)
+	}
+	embedding, err := embedder.Embed(ctx, text)
 	if err != nil {
-		return err
+		return "", err
 	}
 	emb := vector.Embedding{
-		ID:   text, // You may want to use a hash or UUID here
+		ID:   id,
 		Vec:  embedding,
-		Meta: meta,
+		Meta: withText(meta, text),
+	}
+	if err := p.store().Add(ctx, emb); err != nil {
+		return "", err
 	}
-	return p.VectorDB.Add(ctx, emb)
+	return id, nil
 }
 
 // SimilarContext returns the top K most relevant context items for a query.
-func (p *Prompter) SimilarContext(ctx context.Context, query string, topK int) ([]vector.Embedding, error) {
-	if p.LLM == nil || p.VectorDB == nil {
-		return nil, errors.New("LLM and VectorDB must be set")
-	}
-	queryVec, err := p.LLM.Embed(ctx, query)
+// Pass vector.WithFilter to restrict retrieval by metadata (tenant, source, date...)
+// and vector.WithMinScore to drop weak matches. Results carry their similarity score.
+func (p *Prompter) SimilarContext(ctx context.Context, query string, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	queryVec, err := p.embedQuery(ctx, query)
 	if err != nil {
 		return nil, err
 	}
-	return p.VectorDB.Search(ctx, queryVec, topK)
+	return p.store().Search(ctx, queryVec, topK, opts...)
+}
+
+func (p *Prompter) embedQuery(ctx context.Context, query string) ([]float64, error) {
+	embedder := p.embedder()
+	if embedder == nil || p.VectorDB == nil {
+		return nil, errEmbedderUnset
+	}
+	return embedder.Embed(ctx, query)
 }
 
 // Query builds a prompt using the most relevant context and queries the LLM.
+// Context is packed in rank order until the model window (LLM.MaxContext minus the
+// answer reserve) is full. QueryOption values in opts tune retrieval and packing;
+// all other options are passed to the LLM.
 func (p *Prompter) Query(ctx context.Context, prompt string, topK int, opts ...llmproviders.PromptOption) (string, error) {
-	contexts, err := p.SimilarContext(ctx, prompt, topK)
+	res, err := p.QueryDetailed(ctx, prompt, topK, opts...)
 	if err != nil {
 		return "", err
 	}
-	fmt.Printf("len(contexts): %v\n", len(contexts))
-	var contextItems []string
-	for _, emb := range contexts {
-		if metaText, ok := emb.Meta["text"].(string); ok {
-			contextItems = append(contextItems, metaText)
-		} else {
-			contextItems = append(contextItems, emb.ID)
+	return res.Answer, nil
+}
+
+// QueryDetailed is Query but also reports which context items were sent.
+func (p *Prompter) QueryDetailed(ctx context.Context, prompt string, topK int, opts ...llmproviders.PromptOption) (*QueryResult, error) {
+	res, llmOpts, err := p.prepareQuery(ctx, prompt, prompt, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.answer(ctx, res, llmOpts); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// answer asks the generator and fills res.Answer and, when citing, the citations.
+func (p *Prompter) answer(ctx context.Context, res *QueryResult, llmOpts []llmproviders.PromptOption) error {
+	answer, err := p.generator().PromptWithContext(ctx, res.prompt, res.contextItems(), llmOpts...)
+	if err != nil {
+		return err
+	}
+	res.Answer = answer
+	if res.citing {
+		res.ResolveCitations(answer)
+	}
+	return nil
+}
+
+// QueryStream is Query with the answer delivered as it is generated. The returned
+// QueryResult lists the context sent; its Answer stays empty, the final event on the
+// channel carries the full text. Providers without streaming support deliver the
+// whole answer as a single delta.
+func (p *Prompter) QueryStream(ctx context.Context, prompt string, topK int, opts ...llmproviders.PromptOption) (*QueryResult, <-chan llmproviders.StreamEvent, error) {
+	res, llmOpts, err := p.prepareQuery(ctx, prompt, prompt, topK, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	gen := p.generator()
+	if s, ok := gen.(llmproviders.Streamer); ok {
+		events, err := s.PromptWithContextStream(ctx, res.prompt, res.contextItems(), llmOpts...)
+		if err != nil {
+			return nil, nil, err
 		}
+		return res, events, nil
+	}
+	answer, err := gen.PromptWithContext(ctx, res.prompt, res.contextItems(), llmOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	events := make(chan llmproviders.StreamEvent, 2)
+	events <- llmproviders.StreamEvent{Delta: answer}
+	events <- llmproviders.StreamEvent{Done: true, Text: answer}
+	close(events)
+	return res, events, nil
+}
+
+// prepareQuery retrieves context for query, packs it around prompt and returns the
+// LLM options left after consuming QueryOptions. query and prompt differ only in
+// sessions, which retrieve with a condensed question but answer with the history.
+func (p *Prompter) prepareQuery(ctx context.Context, query, prompt string, topK int, opts []llmproviders.PromptOption) (*QueryResult, []llmproviders.PromptOption, error) {
+	if p.generator() == nil {
+		return nil, nil, errGeneratorUnset
+	}
+	cfg, llmOpts := splitQueryOptions(opts)
+	contexts, err := p.selectContext(ctx, query, topK, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := p.packContext(prompt, contexts, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, llmOpts, nil
+}
+
+// selectContext retrieves the topK items for query. With a reranker or MMR it
+// over-fetches a candidate pool, reranks it, then diversifies it.
+func (p *Prompter) selectContext(ctx context.Context, query string, topK int, cfg queryConfig) ([]vector.SearchResult, error) {
+	if cfg.reranker == nil && cfg.mmrLambda == nil {
+		return p.retrieve(ctx, query, nil, topK, cfg)
+	}
+	fetch := cfg.candidates
+	if fetch <= 0 {
+		fetch = topK * defaultCandidateFactor
+	}
+	if fetch < topK {
+		fetch = topK
+	}
+	// MMR needs the query vector only to score relevance without a reranker
+	var queryVec []float64
+	if cfg.mmrLambda != nil && cfg.reranker == nil {
+		var err error
+		if queryVec, err = p.embedQuery(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	cands, err := p.retrieve(ctx, query, queryVec, fetch, cfg)
+	if err != nil || len(cands) == 0 {
+		return cands, err
+	}
+	if cfg.reranker != nil {
+		if cands, err = cfg.reranker.Rerank(ctx, query, cands); err != nil {
+			return nil, err
+		}
+		if cfg.mmrLambda != nil {
+			return vector.MaxMarginalRelevanceByScore(cands, topK, *cfg.mmrLambda), nil
+		}
+	}
+	if cfg.mmrLambda != nil {
+		return vector.MaxMarginalRelevance(queryVec, cands, topK, *cfg.mmrLambda), nil
+	}
+	if len(cands) > topK {
+		cands = cands[:topK]
 	}
-	return p.LLM.PromptWithContext(ctx, prompt, contextItems, opts...)
+	return cands, nil
 }
 
-// ClearContext removes all stored context.
+// ClearContext removes all context stored in the Prompter's namespace.
 func (p *Prompter) ClearContext(ctx context.Context) error {
 	if p.VectorDB == nil {
 		return errors.New("VectorDB must be set")
 	}
-	return p.VectorDB.Clear(ctx)
+	return p.store().Clear(ctx)
 }
@@ -0,0 +1,201 @@
+package context_prompter
+
+import (
+	"fmt"
+	"strings"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+const (
+	defaultAnswerReserve = 1024
+	// items that would be cut below this many tokens are dropped instead
+	minTrimTokens = 32
+)
+
+// QueryOption tunes Prompter.Query. It can be mixed with provider PromptOptions in the
+// same variadic list; Query consumes its own options and forwards the rest to the LLM.
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	answerReserve int
+	search        []vector.SearchOption
+	mode          SearchMode
+	fusion        vector.Fusion
+	reranker      Reranker
+	candidates    int // pool size for reranking and MMR
+	mmrLambda     *float64
+	template      *PromptTemplate
+	citations     bool
+}
+
+// WithFilter restricts retrieval to context whose metadata matches f.
+func WithFilter(f *vector.Filter) QueryOption {
+	return func(c *queryConfig) { c.search = append(c.search, vector.WithFilter(f)) }
+}
+
+// WithAnswerReserve keeps n tokens of the model window free for the answer.
+func WithAnswerReserve(n int) QueryOption {
+	return func(c *queryConfig) { c.answerReserve = n }
+}
+
+// WithMinScore drops retrieved items scoring below min instead of always sending topK.
+func WithMinScore(min float64) QueryOption {
+	return func(c *queryConfig) { c.search = append(c.search, vector.WithMinScore(min)) }
+}
+
+// ContextItem is a retrieved item as it was sent to the model.
+type ContextItem struct {
+	Embedding vector.Embedding
+	Score     float64
+	Text      string
+	Tokens    int
+	Truncated bool
+}
+
+// QueryResult is the answer together with the context that produced it.
+type QueryResult struct {
+	Answer       string
+	Included     []ContextItem
+	Dropped      []vector.Embedding // retrieved but did not fit the token budget
+	PromptTokens int                // estimated tokens sent, prompt plus context
+
+	// with WithCitations: sources cited as [n], and cited numbers that match no item
+	Citations        []Citation
+	InvalidCitations []int
+
+	system string // rendered context sent to the provider
+	prompt string // rendered user message
+	citing bool   // WithCitations was given
+}
+
+func splitQueryOptions(opts []llmproviders.PromptOption) (queryConfig, []llmproviders.PromptOption) {
+	cfg := queryConfig{answerReserve: defaultAnswerReserve}
+	var rest []llmproviders.PromptOption
+	for _, o := range opts {
+		if qo, ok := o.(QueryOption); ok {
+			qo(&cfg)
+			continue
+		}
+		rest = append(rest, o)
+	}
+	return cfg, rest
+}
+
+func (p *Prompter) tokenCounter() TokenCounter {
+	if p.Tokens != nil {
+		return p.Tokens
+	}
+	return ApproxTokenCounter{}
+}
+
+// contextText is the text sent to the model for a stored item.
+func contextText(emb vector.Embedding) string {
+	if metaText, ok := emb.Meta[MetaText].(string); ok {
+		return metaText
+	}
+	return emb.ID
+}
+
+// packContext fits ranked items into the model window after the prompt and the
+// answer reserve, honouring MaxContext as an item cap, and renders the prompt
+// template. Items are counted as rendered. The first item that does not fit is
+// trimmed if enough room is left; everything after it is dropped.
+func (p *Prompter) packContext(prompt string, ranked []vector.SearchResult, cfg queryConfig) (*QueryResult, error) {
+	tmpl, err := p.template(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tc := p.tokenCounter()
+	// the template's fixed text costs tokens even with no items
+	sys, question, err := tmpl.render(prompt, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	res := &QueryResult{PromptTokens: tc.CountTokens(question) + messageOverhead}
+	if sys != "" {
+		res.PromptTokens += tc.CountTokens(sys) + messageOverhead
+	}
+	window := 0
+	if gen := p.generator(); gen != nil {
+		window = gen.MaxContext()
+	}
+	budget := -1 // unlimited
+	if window > 0 {
+		budget = window - cfg.answerReserve - res.PromptTokens
+		if budget < 0 {
+			return nil, fmt.Errorf("prompt needs %d tokens but only %d fit the model window", res.PromptTokens, window-cfg.answerReserve)
+		}
+		if sys == "" {
+			// any context will need a message of its own
+			if budget -= messageOverhead; budget < 0 {
+				budget = 0
+			}
+		}
+	}
+	sepTokens := tc.CountTokens(tmpl.sep)
+	var items []TemplateItem
+	var rendered []string
+	full := false
+	for _, r := range ranked {
+		emb := r.Embedding
+		if full || (p.MaxContext > 0 && len(res.Included) >= p.MaxContext) {
+			res.Dropped = append(res.Dropped, emb)
+			continue
+		}
+		ti := TemplateItem{Number: len(items) + 1, ID: emb.ID, Text: contextText(emb), Score: r.Score, Meta: emb.Meta}
+		text, err := tmpl.renderItem(ti)
+		if err != nil {
+			return nil, err
+		}
+		item := ContextItem{Embedding: emb, Score: r.Score, Text: ti.Text, Tokens: tc.CountTokens(text) + sepTokens}
+		if budget >= 0 && item.Tokens > budget {
+			full = true
+			frame := ti
+			frame.Text = ""
+			empty, err := tmpl.renderItem(frame)
+			if err != nil {
+				return nil, err
+			}
+			room := budget - sepTokens - tc.CountTokens(empty)
+			if room < minTrimTokens {
+				res.Dropped = append(res.Dropped, emb)
+				continue
+			}
+			ti.Text = truncateToTokens(tc, ti.Text, room)
+			if text, err = tmpl.renderItem(ti); err != nil {
+				return nil, err
+			}
+			item.Text = ti.Text
+			item.Tokens = tc.CountTokens(text) + sepTokens
+			item.Truncated = true
+		}
+		if budget >= 0 {
+			budget -= item.Tokens
+		}
+		res.PromptTokens += item.Tokens
+		res.Included = append(res.Included, item)
+		items = append(items, ti)
+		rendered = append(rendered, text)
+	}
+	if len(items) > 0 {
+		hadSystem := sys != ""
+		if sys, question, err = tmpl.render(prompt, items, rendered); err != nil {
+			return nil, err
+		}
+		if !hadSystem {
+			res.PromptTokens += messageOverhead // the context now needs its own message
+		}
+	}
+	res.system, res.prompt, res.citing = sys, question, cfg.citations
+	return res, nil
+}
+
+// contextItems is the rendered context as the providers' contextItems argument.
+func (r *QueryResult) contextItems() []string {
+	if strings.TrimSpace(r.system) == "" {
+		return nil
+	}
+	return []string{r.system}
+}
@@ -0,0 +1,137 @@
+package context_prompter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// fakeGenerator reports a fixed window and answers with reply, or "ok" when reply
+// is nil. It records every call.
+type fakeGenerator struct {
+	window int
+	reply  func(prompt string, contextItems []string) (string, error)
+	calls  []string // prompts, in order
+}
+
+func (g *fakeGenerator) Name() string { return "fake" }
+
+func (g *fakeGenerator) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (string, error) {
+	g.calls = append(g.calls, prompt)
+	if g.reply == nil {
+		return "ok", nil
+	}
+	return g.reply(prompt, contextItems)
+}
+
+func (g *fakeGenerator) MaxContext() int { return g.window }
+
+// wordCounter counts one token per whitespace-separated word.
+type wordCounter struct{}
+
+func (wordCounter) CountTokens(text string) int { return len(strings.Fields(text)) }
+
+func words(n int, w string) string {
+	return strings.TrimSpace(strings.Repeat(w+" ", n))
+}
+
+func ranked(texts ...string) []vector.SearchResult {
+	out := make([]vector.SearchResult, len(texts))
+	for i, t := range texts {
+		out[i] = vector.SearchResult{Embedding: vector.Embedding{ID: fmt.Sprint(i), Meta: map[string]interface{}{MetaText: t}}}
+	}
+	return out
+}
+
+func TestPackContext(t *testing.T) {
+	// the question "q" costs 1 token plus messageOverhead; the default template has no
+	// fixed text, and the context message adds another messageOverhead
+	const question = 1 + messageOverhead
+	tests := []struct {
+		name       string
+		window     int
+		maxContext int
+		tmpl       *PromptTemplate
+		items      []string
+		included   []int // tokens of each included item
+		truncated  int   // index of the trimmed item, or -1
+		dropped    int
+		promptToks int
+	}{
+		{
+			name:   "everything fits",
+			window: 100, items: []string{words(3, "a"), words(4, "b")},
+			included: []int{3, 4}, truncated: -1,
+			promptToks: question + messageOverhead + 7,
+		},
+		{
+			name:   "overflow trimmed to the room left",
+			window: question + messageOverhead + 10 + 40, items: []string{words(10, "a"), words(60, "b"), words(5, "c")},
+			included: []int{10, 40}, truncated: 1, dropped: 1,
+			promptToks: question + messageOverhead + 50,
+		},
+		{
+			name:   "too little room to trim drops the rest",
+			window: question + messageOverhead + 10 + 20, items: []string{words(10, "a"), words(60, "b"), words(5, "c")},
+			included: []int{10}, truncated: -1, dropped: 2,
+			promptToks: question + messageOverhead + 10,
+		},
+		{
+			name:   "item cap",
+			window: 100, maxContext: 2, items: []string{"a", "b", "c"},
+			included: []int{1, 1}, truncated: -1, dropped: 1,
+			promptToks: question + messageOverhead + 2,
+		},
+		{
+			name:   "template overhead",
+			window: 100, tmpl: &PromptTemplate{System: "use these five sources wisely: {{.Context}}"},
+			items:    []string{words(3, "a")},
+			included: []int{3}, truncated: -1,
+			promptToks: question + 5 + messageOverhead + 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Prompter{Generator: &fakeGenerator{window: tt.window}, Tokens: wordCounter{}, MaxContext: tt.maxContext, Template: tt.tmpl}
+			res, err := p.packContext("q", ranked(tt.items...), queryConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Included) != len(tt.included) {
+				t.Fatalf("included %d items, want %d", len(res.Included), len(tt.included))
+			}
+			for i, it := range res.Included {
+				if it.Tokens != tt.included[i] || it.Tokens != (wordCounter{}).CountTokens(it.Text) {
+					t.Errorf("item %d: %d tokens (%q), want %d", i, it.Tokens, it.Text, tt.included[i])
+				}
+				if it.Truncated != (i == tt.truncated) {
+					t.Errorf("item %d: truncated = %v", i, it.Truncated)
+				}
+			}
+			if len(res.Dropped) != tt.dropped {
+				t.Errorf("dropped %d, want %d", len(res.Dropped), tt.dropped)
+			}
+			if res.PromptTokens != tt.promptToks {
+				t.Errorf("prompt tokens = %d, want %d", res.PromptTokens, tt.promptToks)
+			}
+			if tt.window > 0 && res.PromptTokens > tt.window {
+				t.Errorf("prompt tokens %d exceed the window %d", res.PromptTokens, tt.window)
+			}
+		})
+	}
+}
+
+func TestPackContextPromptTooLong(t *testing.T) {
+	p := &Prompter{
+		Generator: &fakeGenerator{window: 10},
+		Tokens:    wordCounter{},
+		Template:  &PromptTemplate{System: "a fixed preamble that is too long {{.Context}}"},
+	}
+	if _, err := p.packContext("q", ranked("a"), queryConfig{}); err == nil {
+		t.Error("a template over the window was accepted")
+	}
+}
@@ -0,0 +1,63 @@
+package context_prompter
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// Reranker reorders retrieved candidates by relevance to the query, best first.
+// Implementations replace Score with their own relevance score and may drop
+// candidates, but must not add new ones.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error)
+}
+
+// WithReranker over-fetches candidates (4*topK when candidates <= 0) and lets r pick
+// the topK sent to the model.
+func WithReranker(r Reranker, candidates int) QueryOption {
+	return func(c *queryConfig) {
+		c.reranker = r
+		if candidates > 0 {
+			c.candidates = candidates
+		}
+	}
+}
+
+// sortByScore orders results best first, keeping the retrieval order on ties.
+func sortByScore(rs []vector.SearchResult) {
+	sort.SliceStable(rs, func(i, j int) bool { return rs[i].Score > rs[j].Score })
+}
+
+// LexicalReranker scores candidates with BM25 computed over the candidate set
+// itself. It needs no model or network call, so it is a cheap first step when the
+// vector ranking ignores exact query terms.
+type LexicalReranker struct {
+	K1 float64  // term frequency saturation, default 1.2
+	B  *float64 // length normalisation, default 0.75; 0 turns it off
+}
+
+func (l LexicalReranker) Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error) {
+	k1, b := l.K1, vector.DefaultBM25B
+	if k1 <= 0 {
+		k1 = vector.DefaultBM25K1
+	}
+	if l.B != nil {
+		b = *l.B
+	}
+	// candidates are keyed by position, since IDs need not be unique across stores
+	idx := vector.NewTextIndex(k1, b)
+	for i, c := range candidates {
+		idx.Add(strconv.Itoa(i), contextText(c.Embedding))
+	}
+	scores := idx.Scores(query)
+	out := make([]vector.SearchResult, len(candidates))
+	for i, c := range candidates {
+		c.Score = scores[strconv.Itoa(i)]
+		out[i] = c
+	}
+	sortByScore(out)
+	return out, nil
+}
@@ -0,0 +1,133 @@
+package context_prompter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// RerankAPI selects the request shape an HTTPReranker sends.
+type RerankAPI int
+
+const (
+	// RerankCohere posts {model, query, documents, top_n}; Cohere and Jina accept it.
+	RerankCohere RerankAPI = iota
+	// RerankTEI posts {query, texts} as Hugging Face text-embeddings-inference does.
+	RerankTEI
+)
+
+// HTTPReranker calls a cross-encoder rerank endpoint. Both response shapes are
+// understood: {"results": [{"index", "relevance_score"}]} (Cohere, Jina) and
+// [{"index", "score"}] (TEI).
+type HTTPReranker struct {
+	URL    string // full endpoint, e.g. https://api.cohere.com/v2/rerank
+	APIKey string // sent as a bearer token when set
+	Model  string
+	API    RerankAPI
+	Client *http.Client // defaults to a client with a 30 second timeout
+}
+
+type rerankScore struct {
+	Index          int      `json:"index"`
+	RelevanceScore *float64 `json:"relevance_score"`
+	Score          *float64 `json:"score"`
+}
+
+func (h *HTTPReranker) Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error) {
+	docs := make([]string, len(candidates))
+	for i, c := range candidates {
+		docs[i] = contextText(c.Embedding)
+	}
+	var reqBody interface{}
+	switch h.API {
+	case RerankTEI:
+		reqBody = map[string]interface{}{"query": query, "texts": docs}
+	default:
+		req := map[string]interface{}{"query": query, "documents": docs, "top_n": len(docs)}
+		if h.Model != "" {
+			req["model"] = h.Model
+		}
+		reqBody = req
+	}
+	body, err := h.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := parseRerankScores(body)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vector.SearchResult, 0, len(scores))
+	for _, s := range scores {
+		if s.Index < 0 || s.Index >= len(candidates) {
+			return nil, fmt.Errorf("rerank: index %d out of range", s.Index)
+		}
+		c := candidates[s.Index]
+		switch {
+		case s.RelevanceScore != nil:
+			c.Score = *s.RelevanceScore
+		case s.Score != nil:
+			c.Score = *s.Score
+		}
+		out = append(out, c)
+	}
+	sortByScore(out)
+	return out, nil
+}
+
+func parseRerankScores(body []byte) ([]rerankScore, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var scores []rerankScore
+		if err := json.Unmarshal(body, &scores); err != nil {
+			return nil, err
+		}
+		return scores, nil
+	}
+	var out struct {
+		Results []rerankScore `json:"results"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if out.Results == nil {
+		return nil, errors.New("rerank: response has no results")
+	}
+	return out.Results, nil
+}
+
+func (h *HTTPReranker) post(ctx context.Context, data interface{}) ([]byte, error) {
+	jsonData, _ := json.Marshal(data)
+	req, err := http.NewRequestWithContext(ctx, "POST", h.URL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.APIKey)
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("rerank error %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
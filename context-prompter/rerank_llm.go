@@ -0,0 +1,76 @@
+package context_prompter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+const llmRerankInstructions = `You grade how well passages answer a question.
+For every passage reply with one line "<passage number>: <score>", where the score
+is 0 (irrelevant) to 10 (fully answers it). Reply with nothing else.`
+
+var llmScoreLine = regexp.MustCompile(`(?m)^\s*\[?(\d+)\]?\s*[:=-]\s*(\d+(?:\.\d+)?)`)
+
+// LLMReranker asks a generator to grade every candidate in a single prompt
+// (LLM-as-judge). Candidates the model does not grade keep their retrieval order
+// after the graded ones.
+type LLMReranker struct {
+	Generator  llmproviders.Generator
+	MaxChars   int // per-passage cap in the grading prompt, default 1000
+	PromptOpts []llmproviders.PromptOption
+}
+
+// NewLLMReranker returns an LLMReranker grading with gen, for example the
+// Prompter's own LLM.
+func NewLLMReranker(gen llmproviders.Generator) *LLMReranker {
+	return &LLMReranker{Generator: gen}
+}
+
+func (l *LLMReranker) Rerank(ctx context.Context, query string, candidates []vector.SearchResult) ([]vector.SearchResult, error) {
+	if l.Generator == nil {
+		return nil, fmt.Errorf("LLMReranker: Generator must be set")
+	}
+	maxChars := l.MaxChars
+	if maxChars <= 0 {
+		maxChars = 1000
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Question: %s\n\n", query)
+	for i, c := range candidates {
+		text := contextText(c.Embedding)
+		if r := []rune(text); len(r) > maxChars {
+			text = string(r[:maxChars])
+		}
+		fmt.Fprintf(&sb, "Passage %d:\n%s\n\n", i+1, text)
+	}
+	reply, err := l.Generator.PromptWithContext(ctx, sb.String(), []string{llmRerankInstructions}, l.PromptOpts...)
+	if err != nil {
+		return nil, err
+	}
+	graded := make(map[int]float64)
+	for _, m := range llmScoreLine.FindAllStringSubmatch(reply, -1) {
+		n, err1 := strconv.Atoi(m[1])
+		score, err2 := strconv.ParseFloat(m[2], 64)
+		if err1 != nil || err2 != nil || n < 1 || n > len(candidates) {
+			continue
+		}
+		graded[n-1] = score
+	}
+	out := make([]vector.SearchResult, len(candidates))
+	for i, c := range candidates {
+		if s, ok := graded[i]; ok {
+			c.Score = s
+		} else {
+			c.Score = -1
+		}
+		out[i] = c
+	}
+	sortByScore(out)
+	return out, nil
+}
@@ -0,0 +1,124 @@
+package context_prompter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+func resultTexts(res []vector.SearchResult) string {
+	out := make([]string, len(res))
+	for i, r := range res {
+		out[i] = contextText(r.Embedding)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestHTTPReranker(t *testing.T) {
+	tests := []struct {
+		name    string
+		api     RerankAPI
+		status  int
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"cohere", RerankCohere, 200, `{"results":[{"index":2,"relevance_score":0.9},{"index":0,"relevance_score":0.4}]}`, "c,a", ""},
+		{"tei", RerankTEI, 200, `[{"index":1,"score":0.2},{"index":0,"score":0.7},{"index":2,"score":0.5}]`, "a,c,b", ""},
+		{"index out of range", RerankCohere, 200, `{"results":[{"index":3,"relevance_score":0.9}]}`, "", "out of range"},
+		{"negative index", RerankTEI, 200, `[{"index":-1,"score":0.9}]`, "", "out of range"},
+		{"no results", RerankCohere, 200, `{"id":"x"}`, "", "no results"},
+		{"error status", RerankCohere, 429, `{"message":"rate limited"}`, "", "rerank error 429"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer key" {
+					t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+				}
+				json.NewDecoder(r.Body).Decode(&got)
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer srv.Close()
+			h := &HTTPReranker{URL: srv.URL, APIKey: "key", Model: "rerank-test", API: tt.api}
+			res, err := h.Rerank(context.Background(), "q", ranked("a", "b", "c"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := resultTexts(res); s != tt.want {
+				t.Errorf("order %s, want %s", s, tt.want)
+			}
+			if tt.api == RerankTEI {
+				if got["texts"] == nil || got["documents"] != nil || got["model"] != nil {
+					t.Errorf("TEI request = %v", got)
+				}
+			} else if got["documents"] == nil || got["model"] != "rerank-test" || got["top_n"] != 3.0 {
+				t.Errorf("Cohere request = %v", got)
+			}
+		})
+	}
+}
+
+func TestLLMReranker(t *testing.T) {
+	gen := &fakeGenerator{reply: func(prompt string, contextItems []string) (string, error) {
+		if len(contextItems) != 1 || contextItems[0] != llmRerankInstructions {
+			t.Errorf("instructions = %q", contextItems)
+		}
+		// bracketed numbers, decimals, out-of-range and chatter are all tolerated;
+		// passage 3 is left ungraded
+		return "Sure.\n[2]: 9\n4 - 7.5\n1= 3\n9: 10\n", nil
+	}}
+	res, err := NewLLMReranker(gen).Rerank(context.Background(), "q", ranked("a", "b", "c", "d", "e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := resultTexts(res); s != "b,d,a,c,e" {
+		t.Errorf("order %s, want b,d,a,c,e", s)
+	}
+	scores := []float64{9, 7.5, 3, -1, -1}
+	for i, r := range res {
+		if r.Score != scores[i] {
+			t.Errorf("%s: score %v, want %v", contextText(r.Embedding), r.Score, scores[i])
+		}
+	}
+	if !strings.Contains(gen.calls[0], "Passage 5:\ne") {
+		t.Errorf("prompt = %q", gen.calls[0])
+	}
+	if _, err := (&LLMReranker{}).Rerank(context.Background(), "q", nil); err == nil {
+		t.Error("nil Generator accepted")
+	}
+}
+
+func TestLexicalReranker(t *testing.T) {
+	cands := ranked("refund policy", "ERR_42 refund", "refund refund refund and some other words here")
+	res, _ := LexicalReranker{}.Rerank(context.Background(), "err_42 refund", cands)
+	if contextText(res[0].Embedding) != "ERR_42 refund" {
+		t.Errorf("order %s", resultTexts(res))
+	}
+
+	// b=0 ignores length, so the repeated term wins; by default the long text loses
+	cands = ranked("refund", "refund refund and lots of other words in a much longer text")
+	zero := 0.0
+	res, _ = LexicalReranker{B: &zero}.Rerank(context.Background(), "refund", cands)
+	if contextText(res[0].Embedding) != "refund refund and lots of other words in a much longer text" {
+		t.Errorf("b=0: order %s", resultTexts(res))
+	}
+	res, _ = LexicalReranker{}.Rerank(context.Background(), "refund", cands)
+	if contextText(res[0].Embedding) != "refund" {
+		t.Errorf("default b: order %s", resultTexts(res))
+	}
+}
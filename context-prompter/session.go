@@ -0,0 +1,302 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// Message roles in a session history.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+const (
+	// history budget when the generator does not report its window
+	defaultHistoryTokens = 1024
+	// most recent messages that are never summarised away
+	keepRecentMessages = 4
+)
+
+// ErrSessionNotFound is returned by SessionStore.Load for an unknown ID.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Message is one turn of a conversation.
+type Message struct {
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	Time    time.Time `json:"time"`
+}
+
+// SessionState is what a SessionStore persists: the summary of turns that no longer
+// fit and the recent messages verbatim.
+type SessionState struct {
+	ID       string    `json:"id"`
+	Summary  string    `json:"summary,omitempty"`
+	Messages []Message `json:"messages"`
+}
+
+// SessionStore keeps session state between requests and restarts.
+type SessionStore interface {
+	// Load returns the state saved under id, or ErrSessionNotFound.
+	Load(ctx context.Context, id string) (*SessionState, error)
+	Save(ctx context.Context, state *SessionState) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MemorySessionStore is a SessionStore in process memory.
+type MemorySessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]SessionState
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]SessionState)}
+}
+
+func (m *MemorySessionStore) Load(ctx context.Context, id string) (*SessionState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	st, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	st.Messages = append([]Message(nil), st.Messages...)
+	return &st, nil
+}
+
+func (m *MemorySessionStore) Save(ctx context.Context, state *SessionState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := *state
+	st.Messages = append([]Message(nil), state.Messages...)
+	m.sessions[state.ID] = st
+	return nil
+}
+
+func (m *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+// Session is a multi-turn conversation on top of a Prompter. Each Ask condenses the
+// history and the new question into a standalone query for retrieval, answers with
+// the history in the prompt, and keeps the history within HistoryTokens by folding
+// the oldest turns into a running summary. State is saved to Store after every turn
+// and reloaded before the next, so instances sharing a store continue each other's
+// conversations; two Asks on the same ID running at once still race, and the last
+// save wins.
+type Session struct {
+	Prompter *Prompter
+	Store    SessionStore
+	// HistoryTokens caps the history sent with each question; 0 uses a quarter of
+	// the generator's window, or 1024 tokens when it is unknown.
+	HistoryTokens int
+	// DropOldTurns discards turns that do not fit instead of summarising them, which
+	// saves a model call per compaction.
+	DropOldTurns bool
+
+	mu    sync.Mutex
+	state SessionState
+}
+
+// NewSession returns the session saved under id in store, or a new empty one.
+func (p *Prompter) NewSession(ctx context.Context, id string, store SessionStore) (*Session, error) {
+	if store == nil {
+		store = NewMemorySessionStore()
+	}
+	s := &Session{Prompter: p, Store: store, state: SessionState{ID: id}}
+	st, err := store.Load(ctx, id)
+	switch {
+	case err == nil:
+		s.state = *st
+	case !errors.Is(err, ErrSessionNotFound):
+		return nil, err
+	}
+	return s, nil
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.ID
+}
+
+// History returns the summary of older turns and a copy of the recent messages.
+func (s *Session) History() (string, []Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Summary, append([]Message(nil), s.state.Messages...)
+}
+
+// Ask answers question in the context of the conversation so far and records the
+// turn. opts are the same as for Prompter.Query. The state is first reloaded from
+// Store to pick up turns saved by other instances. The turn is saved before the
+// history is compacted. If saving or compacting fails once the answer exists, Ask
+// returns the answer together with the error; a failed compaction is retried on
+// the next turn.
+func (s *Session) Ask(ctx context.Context, question string, topK int, opts ...llmproviders.PromptOption) (*QueryResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.Prompter
+	if p.generator() == nil {
+		return nil, errGeneratorUnset
+	}
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+	query := question
+	history := s.historyText()
+	if history != "" {
+		var err error
+		if query, err = s.condense(ctx, history, question); err != nil {
+			return nil, fmt.Errorf("condense question: %w", err)
+		}
+	}
+	prompt := question
+	if history != "" {
+		prompt = history + "\n\nCurrent question: " + question
+	}
+	res, llmOpts, err := p.prepareQuery(ctx, query, prompt, topK, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.answer(ctx, res, llmOpts); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s.state.Messages = append(s.state.Messages,
+		Message{Role: RoleUser, Content: question, Time: now},
+		Message{Role: RoleAssistant, Content: res.Answer, Time: now})
+	if err := s.Store.Save(ctx, &s.state); err != nil {
+		return res, err
+	}
+	changed, err := s.compact(ctx)
+	if err != nil {
+		return res, fmt.Errorf("compact history: %w", err)
+	}
+	if changed {
+		if err := s.Store.Save(ctx, &s.state); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// reload replaces the state with the one in Store; a session missing there, never
+// saved or deleted by another instance, starts empty.
+func (s *Session) reload(ctx context.Context) error {
+	st, err := s.Store.Load(ctx, s.state.ID)
+	switch {
+	case err == nil:
+		s.state = *st
+	case errors.Is(err, ErrSessionNotFound):
+		s.state = SessionState{ID: s.state.ID}
+	default:
+		return fmt.Errorf("load session: %w", err)
+	}
+	return nil
+}
+
+// Reset forgets the conversation and deletes it from the store.
+func (s *Session) Reset(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = SessionState{ID: s.state.ID}
+	return s.Store.Delete(ctx, s.state.ID)
+}
+
+const condenseInstructions = `Rewrite the user's latest question as a standalone question that can be understood without the conversation, resolving pronouns and references from it. Reply with the question only.`
+
+// condense turns the latest question into a standalone retrieval query.
+func (s *Session) condense(ctx context.Context, history, question string) (string, error) {
+	prompt := history + "\n\nLatest question: " + question
+	out, err := s.Prompter.generator().PromptWithContext(ctx, prompt, []string{condenseInstructions})
+	if err != nil {
+		return "", err
+	}
+	if out = strings.TrimSpace(out); out == "" {
+		return question, nil
+	}
+	return out, nil
+}
+
+// historyText renders the summary and messages for a prompt.
+func (s *Session) historyText() string {
+	return renderHistory(s.state.Summary, s.state.Messages)
+}
+
+func renderHistory(summary string, msgs []Message) string {
+	var sb strings.Builder
+	if summary != "" {
+		sb.WriteString("Summary of the earlier conversation: ")
+		sb.WriteString(summary)
+	}
+	if len(msgs) > 0 {
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("Conversation so far:")
+		for _, m := range msgs {
+			role := "User"
+			if m.Role == RoleAssistant {
+				role = "Assistant"
+			}
+			fmt.Fprintf(&sb, "\n%s: %s", role, m.Content)
+		}
+	}
+	return sb.String()
+}
+
+func (s *Session) historyBudget() int {
+	if s.HistoryTokens > 0 {
+		return s.HistoryTokens
+	}
+	if gen := s.Prompter.generator(); gen != nil && gen.MaxContext() > 0 {
+		return gen.MaxContext() / 4
+	}
+	return defaultHistoryTokens
+}
+
+const summarizeInstructions = `Summarize the conversation below in a few sentences, keeping names, facts, numbers and open questions the user may refer back to. Include the earlier summary if there is one. Reply with the summary only.`
+
+// compact folds the oldest messages into the summary (or drops them) until the
+// history fits the budget, always keeping the most recent messages verbatim. It
+// reports whether the state changed; on error it is left as it was.
+func (s *Session) compact(ctx context.Context) (bool, error) {
+	tc := s.Prompter.tokenCounter()
+	budget := s.historyBudget()
+	if tc.CountTokens(s.historyText()) <= budget {
+		return false, nil
+	}
+	changed := false
+	if len(s.state.Messages) > keepRecentMessages {
+		n := len(s.state.Messages) - keepRecentMessages
+		if !s.DropOldTurns {
+			summary, err := s.Prompter.generator().PromptWithContext(ctx,
+				renderHistory(s.state.Summary, s.state.Messages[:n]), []string{summarizeInstructions})
+			if err != nil {
+				return false, err
+			}
+			s.state.Summary = strings.TrimSpace(summary)
+		}
+		s.state.Messages = append([]Message(nil), s.state.Messages[n:]...)
+		changed = true
+	}
+	// a runaway summary or a few huge recent turns can still exceed the budget
+	if over := tc.CountTokens(s.historyText()) - budget; over > 0 && s.state.Summary != "" {
+		summary := truncateToTokens(tc, s.state.Summary, tc.CountTokens(s.state.Summary)-over)
+		changed = changed || summary != s.state.Summary
+		s.state.Summary = summary
+	}
+	return changed, nil
+}
@@ -0,0 +1,198 @@
+package context_prompter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// sessionGenerator answers condense requests with "ERR_42", summary requests with
+// summary (or summaryErr) and questions with ten words.
+func sessionGenerator(summary string, summaryErr error) *fakeGenerator {
+	return &fakeGenerator{reply: func(prompt string, contextItems []string) (string, error) {
+		switch {
+		case len(contextItems) == 1 && contextItems[0] == condenseInstructions:
+			return "ERR_42", nil
+		case len(contextItems) == 1 && contextItems[0] == summarizeInstructions:
+			return summary, summaryErr
+		}
+		return words(10, "answer"), nil
+	}}
+}
+
+// failStore is a MemorySessionStore whose Save fails while failSave is set.
+type failStore struct {
+	*MemorySessionStore
+	failSave bool
+}
+
+func (f *failStore) Save(ctx context.Context, st *SessionState) error {
+	if f.failSave {
+		return errors.New("save failed")
+	}
+	return f.MemorySessionStore.Save(ctx, st)
+}
+
+func newTestSession(t *testing.T, gen *fakeGenerator, store SessionStore) *Session {
+	t.Helper()
+	p, _ := hybridPrompter(t)
+	p.Generator, p.Tokens = gen, wordCounter{}
+	s, err := p.NewSession(context.Background(), "s1", store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSessionCondenses(t *testing.T) {
+	ctx := context.Background()
+	gen := sessionGenerator("", nil)
+	s := newTestSession(t, gen, nil)
+	if _, err := s.Ask(ctx, "What does ERR_42 mean?", 1, WithSearchMode(SearchKeyword)); err != nil {
+		t.Fatal(err)
+	}
+	if len(gen.calls) != 1 {
+		t.Errorf("first turn made %d calls, want no condense call", len(gen.calls))
+	}
+	// the follow-up alone matches nothing; its condensed form finds the document
+	res, err := s.Ask(ctx, "and how do I fix it?", 1, WithSearchMode(SearchKeyword))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Included) != 1 || res.Included[0].Embedding.ID != "code" {
+		t.Errorf("retrieved %+v, want the condensed query's match", res.Included)
+	}
+	if len(gen.calls) != 3 || !strings.HasSuffix(gen.calls[1], "Latest question: and how do I fix it?") {
+		t.Errorf("calls = %q", gen.calls)
+	}
+	if !strings.Contains(gen.calls[2], "User: What does ERR_42 mean?") || !strings.HasSuffix(gen.calls[2], "Current question: and how do I fix it?") {
+		t.Errorf("answer prompt = %q", gen.calls[2])
+	}
+}
+
+func TestSessionCompacts(t *testing.T) {
+	tests := []struct {
+		name        string
+		drop        bool
+		wantSummary string
+	}{
+		{"summarise", false, "they asked about errors"},
+		{"drop old turns", true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			gen := sessionGenerator("they asked about errors", nil)
+			store := NewMemorySessionStore()
+			s := newTestSession(t, gen, store)
+			s.HistoryTokens, s.DropOldTurns = 40, tt.drop
+			for i := 0; i < 3; i++ {
+				if _, err := s.Ask(ctx, "next question", 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+			summary, msgs := s.History()
+			if summary != tt.wantSummary || len(msgs) != keepRecentMessages {
+				t.Errorf("summary %q and %d messages, want %q and %d", summary, len(msgs), tt.wantSummary, keepRecentMessages)
+			}
+			// condense and answer prompts end with the question; summary prompts do not
+			summarised := false
+			for _, c := range gen.calls {
+				if !strings.Contains(c, "Latest question") && !strings.Contains(c, "Current question") && c != "next question" {
+					summarised = true
+				}
+			}
+			if summarised == tt.drop {
+				t.Errorf("summarise call made = %v", summarised)
+			}
+			saved, _ := store.Load(ctx, "s1")
+			if saved.Summary != summary || len(saved.Messages) != len(msgs) {
+				t.Errorf("compacted state not saved: %+v", saved)
+			}
+		})
+	}
+}
+
+func TestCompactReportsChanges(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSession(t, sessionGenerator("short", nil), nil)
+	s.HistoryTokens = 5
+	s.state.Messages = []Message{{Role: RoleUser, Content: words(20, "q")}, {Role: RoleAssistant, Content: "a"}}
+	// over budget, but nothing old enough to fold away and no summary to cut
+	if changed, err := s.compact(ctx); err != nil || changed {
+		t.Errorf("compact = %v, %v; want no change", changed, err)
+	}
+	s.HistoryTokens = 100
+	if changed, _ := s.compact(ctx); changed {
+		t.Error("compact under budget reported a change")
+	}
+	s.HistoryTokens = 30
+	s.state.Summary = words(40, "s")
+	if changed, _ := s.compact(ctx); !changed || len(strings.Fields(s.state.Summary)) >= 40 {
+		t.Errorf("oversized summary not cut: %d words", len(strings.Fields(s.state.Summary)))
+	}
+}
+
+func TestSessionSaveAndCompactErrors(t *testing.T) {
+	ctx := context.Background()
+	store := &failStore{MemorySessionStore: NewMemorySessionStore()}
+	s := newTestSession(t, sessionGenerator("", errors.New("summariser down")), store)
+	s.HistoryTokens = 40
+	for i := 0; i < 2; i++ {
+		if _, err := s.Ask(ctx, "next question", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the third turn is saved, then compaction fails; the answer is still returned
+	res, err := s.Ask(ctx, "next question", 1)
+	if err == nil || !strings.Contains(err.Error(), "summariser down") || res == nil || res.Answer == "" {
+		t.Fatalf("Ask = %v, %v; want the answer and the compaction error", res, err)
+	}
+	if saved, _ := store.Load(ctx, "s1"); len(saved.Messages) != 6 || saved.Summary != "" {
+		t.Errorf("saved %d messages and summary %q, want the uncompacted turn", len(saved.Messages), saved.Summary)
+	}
+
+	store.failSave = true
+	res, err = s.Ask(ctx, "next question", 1)
+	if err == nil || res == nil {
+		t.Errorf("Ask with a failing store = %v, %v; want the answer and the error", res, err)
+	}
+}
+
+func TestSessionSharedStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore()
+	a := newTestSession(t, sessionGenerator("", nil), store)
+	b := newTestSession(t, sessionGenerator("", nil), store)
+	if _, err := a.Ask(ctx, "first", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Ask(ctx, "second", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Ask(ctx, "third", 1); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := store.Load(ctx, "s1")
+	var questions []string
+	for _, m := range saved.Messages {
+		if m.Role == RoleUser {
+			questions = append(questions, m.Content)
+		}
+	}
+	if strings.Join(questions, ",") != "first,second,third" {
+		t.Errorf("questions = %v, want every instance's turn", questions)
+	}
+
+	// a reset on one instance is seen by the other
+	if err := b.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Ask(ctx, "fresh", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, msgs := a.History(); len(msgs) != 2 {
+		t.Errorf("history after reset has %d messages, want 2", len(msgs))
+	}
+}
@@ -0,0 +1,182 @@
+package context_prompter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplate controls how retrieved context and the question are worded. Each
+// field is a text/template; empty fields fall back to the "default" built-in. The
+// rendered System text is sent to the provider as the context (its system prompt,
+// or additional instructions for OpenAI assistants) and the rendered Question as
+// the user message.
+type PromptTemplate struct {
+	System    string // rendered with TemplateData; place {{.Context}} where the items go
+	Item      string // rendered once per item with TemplateItem
+	Separator string // placed between rendered items
+	Question  string // rendered with TemplateData
+}
+
+// TemplateItem is the data for PromptTemplate.Item.
+type TemplateItem struct {
+	Number int // 1-based position in the prompt
+	ID     string
+	Text   string
+	Score  float64
+	Meta   map[string]interface{}
+}
+
+// TemplateData is the data for PromptTemplate.System and Question.
+type TemplateData struct {
+	Question string
+	Context  string // rendered items joined by Separator
+	Items    []TemplateItem
+}
+
+// Names of the built-in templates.
+const (
+	TemplateDefault  = "default"
+	TemplateGrounded = "grounded"
+	TemplateNumbered = "numbered"
+	TemplateCited    = "cited" // numbered, and asks for [n] citations; used by WithCitations
+)
+
+var builtinTemplates = map[string]PromptTemplate{
+	// the items as they are, which is what Query has always sent
+	TemplateDefault: {
+		System:    "{{.Context}}",
+		Item:      "{{.Text}}",
+		Separator: "\n\n",
+		Question:  "{{.Question}}",
+	},
+	TemplateGrounded: {
+		System: "Answer the question using only the sources below. If they do not contain " +
+			"the answer, say that you don't know.\n\nSources:\n\n{{.Context}}",
+		Item:      "{{.Text}}",
+		Separator: "\n\n---\n\n",
+		Question:  "{{.Question}}",
+	},
+	TemplateNumbered: {
+		System: "Answer the question using only the numbered sources below. If they do not " +
+			"contain the answer, say that you don't know.\n\n{{.Context}}",
+		Item:      `[{{.Number}}]{{with index .Meta "source"}} ({{.}}){{end}} {{.Text}}`,
+		Separator: "\n\n",
+		Question:  "{{.Question}}",
+	},
+	TemplateCited: {
+		System: "Answer the question using only the numbered sources below. Cite every source " +
+			"you use by its number in square brackets, like [1] or [2][3]. If the sources do " +
+			"not contain the answer, say that you don't know.\n\n{{.Context}}",
+		Item:      `[{{.Number}}]{{with index .Meta "source"}} ({{.}}){{end}} {{.Text}}`,
+		Separator: "\n\n",
+		Question:  "{{.Question}}",
+	},
+}
+
+// BuiltinTemplate returns the named built-in template: "default", "grounded" (answer
+// only from the sources), "numbered" (sources labelled [1], [2], ... with their
+// "source" metadata) or "cited" (numbered, asking for [n] citations).
+func BuiltinTemplate(name string) (PromptTemplate, error) {
+	t, ok := builtinTemplates[name]
+	if !ok {
+		return PromptTemplate{}, fmt.Errorf("unknown prompt template %q", name)
+	}
+	return t, nil
+}
+
+// WithTemplate sets the prompt template for one Query, overriding Prompter.Template.
+func WithTemplate(t PromptTemplate) QueryOption {
+	return func(c *queryConfig) { c.template = &t }
+}
+
+// compiledTemplate is a parsed PromptTemplate.
+type compiledTemplate struct {
+	system, item, question *template.Template
+	sep                    string
+}
+
+func (t PromptTemplate) compile() (*compiledTemplate, error) {
+	def := builtinTemplates[TemplateDefault]
+	if t.System == "" {
+		t.System = def.System
+	}
+	if t.Item == "" {
+		t.Item = def.Item
+	}
+	if t.Question == "" {
+		t.Question = def.Question
+	}
+	if t.Separator == "" {
+		t.Separator = def.Separator
+	}
+	ct := &compiledTemplate{sep: t.Separator}
+	var err error
+	if ct.system, err = template.New("system").Parse(t.System); err != nil {
+		return nil, err
+	}
+	if ct.item, err = template.New("item").Parse(t.Item); err != nil {
+		return nil, err
+	}
+	if ct.question, err = template.New("question").Parse(t.Question); err != nil {
+		return nil, err
+	}
+	return ct, nil
+}
+
+func (ct *compiledTemplate) renderItem(it TemplateItem) (string, error) {
+	var sb strings.Builder
+	if err := ct.item.Execute(&sb, it); err != nil {
+		return "", fmt.Errorf("render context item: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// render returns the context text and the user message for question and the
+// already rendered items.
+func (ct *compiledTemplate) render(question string, items []TemplateItem, rendered []string) (string, string, error) {
+	data := TemplateData{Question: question, Context: strings.Join(rendered, ct.sep), Items: items}
+	var sys, q strings.Builder
+	if err := ct.system.Execute(&sys, data); err != nil {
+		return "", "", fmt.Errorf("render system prompt: %w", err)
+	}
+	if err := ct.question.Execute(&q, data); err != nil {
+		return "", "", fmt.Errorf("render question: %w", err)
+	}
+	return sys.String(), q.String(), nil
+}
+
+// template returns the template for this query: the option, Prompter.Template, the
+// citing template with WithCitations, or the default. With WithCitations a custom
+// template must number its items, or the [n] in the answer would resolve to the
+// wrong sources.
+func (p *Prompter) template(cfg queryConfig) (*compiledTemplate, error) {
+	var t PromptTemplate
+	switch {
+	case cfg.template != nil:
+		t = *cfg.template
+	case p.Template != nil:
+		t = *p.Template
+	case cfg.citations:
+		return builtinTemplates[TemplateCited].compile()
+	default:
+		return builtinTemplates[TemplateDefault].compile()
+	}
+	ct, err := t.compile()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.citations && !ct.numbersItems() {
+		return nil, errors.New("WithCitations: the prompt template must label each item with {{.Number}}; " +
+			`start from BuiltinTemplate("cited")`)
+	}
+	return ct, nil
+}
+
+// numbersItems reports whether the item template renders TemplateItem.Number.
+func (ct *compiledTemplate) numbersItems() bool {
+	a, errA := ct.renderItem(TemplateItem{Number: 1})
+	b, errB := ct.renderItem(TemplateItem{Number: 2})
+	return errA == nil && errB == nil && a != b
+}
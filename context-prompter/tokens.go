@@ -0,0 +1,60 @@
+package context_prompter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenCounter counts tokens the way the target model's tokenizer would.
+type TokenCounter interface {
+	CountTokens(text string) int
+}
+
+// ApproxTokenCounter estimates tokens without a tokenizer: roughly four bytes per
+// token for English text, but never fewer tokens than words.
+type ApproxTokenCounter struct{}
+
+func (ApproxTokenCounter) CountTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	byBytes := (len(text) + 3) / 4
+	if words := len(strings.Fields(text)); words > byBytes {
+		return words
+	}
+	return byBytes
+}
+
+// messageOverhead approximates the tokens a chat API spends framing one message.
+const messageOverhead = 4
+
+// truncateToTokens returns the longest rune prefix of text that fits in max tokens.
+func truncateToTokens(tc TokenCounter, text string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if tc.CountTokens(text) <= max {
+		return text
+	}
+	lo, hi := 0, utf8.RuneCountInString(text)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if tc.CountTokens(runePrefix(text, mid)) <= max {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return runePrefix(text, lo)
+}
+
+func runePrefix(s string, n int) string {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
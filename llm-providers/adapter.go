@@ -0,0 +1,75 @@
+package llmproviders
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// EmbedderFromLLM exposes the embedding side of an LLM as an Embedder. Providers that
+// already implement Embedder are returned as is; others get a sequential EmbedBatch.
+func EmbedderFromLLM(llm LLM) Embedder {
+	if e, ok := llm.(Embedder); ok {
+		return e
+	}
+	return &llmEmbedder{llm: llm}
+}
+
+type llmEmbedder struct {
+	llm  LLM
+	dims int64
+}
+
+func (e *llmEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	vec, err := e.llm.Embed(ctx, text)
+	if err == nil {
+		atomic.StoreInt64(&e.dims, int64(len(vec)))
+	}
+	return vec, err
+}
+
+func (e *llmEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, t := range texts {
+		vec, err := e.Embed(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func (e *llmEmbedder) Dimensions() int {
+	return int(atomic.LoadInt64(&e.dims))
+}
+
+// Combine pairs an Embedder and a Generator into an LLM, for code that still expects
+// a single provider. Streaming is forwarded when the generator supports it.
+func Combine(e Embedder, g Generator) LLM {
+	if s, ok := g.(Streamer); ok {
+		return &streamingCombined{combined{e, g}, s}
+	}
+	return &combined{e, g}
+}
+
+type combined struct {
+	Embedder
+	gen Generator
+}
+
+func (c *combined) Name() string {
+	return c.gen.Name()
+}
+
+func (c *combined) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...PromptOption) (string, error) {
+	return c.gen.PromptWithContext(ctx, prompt, contextItems, opts...)
+}
+
+func (c *combined) MaxContext() int {
+	return c.gen.MaxContext()
+}
+
+type streamingCombined struct {
+	combined
+	Streamer
+}
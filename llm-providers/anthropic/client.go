@@ -0,0 +1,209 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// Anthropic Claude provider on top of the Messages API. Retrieved context goes into
+// the system prompt; embeddings are delegated to a separate Embedder.
+
+const (
+	anthropicBase     = "https://api.anthropic.com/v1"
+	anthropicVersion  = "2023-06-01"
+	DefaultModel      = "claude-sonnet-4-5"
+	defaultMaxTokens  = 1024
+	defaultMaxContext = 200000
+)
+
+// ErrNoEmbedder is returned by Embed when no Embedder is configured.
+var ErrNoEmbedder = errors.New("anthropic has no embeddings endpoint; configure an Embedder")
+
+// NewClient creates a new Anthropic client with default settings.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    anthropicBase,
+		version:    anthropicVersion,
+		model:      DefaultModel,
+		maxTokens:  defaultMaxTokens,
+		maxContext: defaultMaxContext,
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+func New(cfg ...llmproviders.PromptOption) (*Client, error) {
+	if len(cfg) == 0 {
+		return nil, fmt.Errorf("no config provided for anthropic")
+	}
+	c, ok := cfg[0].(AnthropicConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config for anthropic")
+	}
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("anthropic: APIKey is required")
+	}
+	client := NewClient(c.APIKey)
+	if c.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(c.BaseURL, "/")
+	}
+	if c.Version != "" {
+		client.version = c.Version
+	}
+	if c.Model != "" {
+		client.model = c.Model
+	}
+	if c.MaxTokens > 0 {
+		client.maxTokens = c.MaxTokens
+	}
+	if c.MaxContext > 0 {
+		client.maxContext = c.MaxContext
+	}
+	client.embedder = c.Embedder
+	return client, nil
+}
+
+// SetEmbedder pairs the client with a separate embeddings provider.
+func (c *Client) SetEmbedder(e llmproviders.Embedder) {
+	c.embedder = e
+}
+
+// Name returns the name of the LLM provider.
+func (c *Client) Name() string {
+	return "anthropic"
+}
+
+// Embed delegates to the configured Embedder.
+func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
+	if c.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	return c.embedder.Embed(ctx, text)
+}
+
+// MaxContext returns the context window of the configured model in tokens.
+func (c *Client) MaxContext() int {
+	return c.maxContext
+}
+
+// PromptWithContext sends prompt as the user message with the context items joined
+// into the system prompt. Accepts Model, Temperature and MaxTokens options.
+func (c *Client) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (string, error) {
+	reqBody := messagesRequest{
+		Model:     c.model,
+		MaxTokens: c.maxTokens,
+		System:    strings.Join(contextItems, "\n\n"),
+		Messages:  []message{{Role: "user", Content: prompt}},
+	}
+	for _, o := range opts {
+		switch v := o.(type) {
+		case Model:
+			reqBody.Model = string(v)
+		case Temperature:
+			t := float64(v)
+			reqBody.Temperature = &t
+		case MaxTokens:
+			reqBody.MaxTokens = int(v)
+		}
+	}
+	body, err := c.post(ctx, "messages", reqBody)
+	if err != nil {
+		return "", err
+	}
+	var out messagesResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("no text content returned (stop_reason %s)", out.StopReason)
+	}
+	return sb.String(), nil
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, data interface{}) ([]byte, error) {
+	jsonData, _ := json.Marshal(data)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.baseURL, endpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", c.version)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, parseError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// parseError maps an Anthropic error body to *APIError.
+func parseError(status int, body []byte) error {
+	var env struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	apiErr := &APIError{StatusCode: status}
+	if err := json.Unmarshal(body, &env); err == nil && env.Error.Type != "" {
+		apiErr.Type = env.Error.Type
+		apiErr.Message = env.Error.Message
+	} else {
+		// not an Anthropic error body (a proxy page, a wrong BaseURL): classify by
+		// status so only server errors and 429 are retried
+		apiErr.Type = statusErrorType(status)
+		apiErr.Message = string(body)
+	}
+	return apiErr
+}
+
+// statusErrorType returns the error type the API documents for status.
+func statusErrorType(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimit
+	case status == 529:
+		return ErrOverloaded
+	case status >= 500:
+		return ErrAPI
+	case status == http.StatusUnauthorized:
+		return ErrAuthentication
+	case status == http.StatusForbidden:
+		return ErrPermission
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrRequestTooLarge
+	default:
+		return ErrInvalidRequest
+	}
+}
+
+var (
+	_ llmproviders.LLM       = &Client{}
+	_ llmproviders.Generator = &Client{}
+)
@@ -0,0 +1,165 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testClient returns a client pointed at a test server running handler.
+func testClient(t *testing.T, cfg AnthropicConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	if cfg.APIKey == "" {
+		cfg.APIKey = "key"
+	}
+	cfg.BaseURL = srv.URL + "/"
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestMessages(t *testing.T) {
+	var got map[string]interface{}
+	c := testClient(t, AnthropicConfig{Model: "claude-test", MaxTokens: 200}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("headers = %v", r.Header)
+		}
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"Hello"},{"type":"tool_use"},{"type":"text","text":" world"}],"stop_reason":"end_turn"}`)
+	})
+	answer, err := c.PromptWithContext(context.Background(), "hi", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Hello world" {
+		t.Errorf("answer = %q", answer)
+	}
+	if got["system"] != "a\n\nb" {
+		t.Errorf("system = %v", got["system"])
+	}
+	msgs, _ := got["messages"].([]interface{})
+	if len(msgs) != 1 || msgs[0].(map[string]interface{})["content"] != "hi" || msgs[0].(map[string]interface{})["role"] != "user" {
+		t.Errorf("messages = %v", got["messages"])
+	}
+	if got["model"] != "claude-test" || got["max_tokens"] != 200.0 {
+		t.Errorf("model %v, max_tokens %v", got["model"], got["max_tokens"])
+	}
+	if _, ok := got["temperature"]; ok {
+		t.Error("temperature sent without the option")
+	}
+
+	// no context: the system field is omitted
+	if _, err := c.PromptWithContext(context.Background(), "hi", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["system"]; ok {
+		t.Errorf("system = %v, want it omitted", got["system"])
+	}
+}
+
+func TestPromptOptions(t *testing.T) {
+	var got map[string]interface{}
+	c := testClient(t, AnthropicConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"content":[{"type":"text","text":"ok"}]}`)
+	})
+	_, err := c.PromptWithContext(context.Background(), "hi", nil, Model("claude-other"), Temperature(0), MaxTokens(50), "unknown option")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["model"] != "claude-other" || got["max_tokens"] != 50.0 {
+		t.Errorf("model %v, max_tokens %v", got["model"], got["max_tokens"])
+	}
+	// a zero temperature is sent, not dropped
+	if temp, ok := got["temperature"]; !ok || temp != 0.0 {
+		t.Errorf("temperature = %v, %v", temp, ok)
+	}
+}
+
+func TestEmptyAnswer(t *testing.T) {
+	c := testClient(t, AnthropicConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":[],"stop_reason":"max_tokens"}`)
+	})
+	if _, err := c.PromptWithContext(context.Background(), "hi", nil); err == nil {
+		t.Error("empty content accepted")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantType  string
+		retryable bool
+	}{
+		{"invalid request", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: required"}}`, ErrInvalidRequest, false},
+		{"auth", 401, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`, ErrAuthentication, false},
+		{"rate limit", 429, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`, ErrRateLimit, true},
+		{"overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrOverloaded, true},
+		{"server error", 500, `{"type":"error","error":{"type":"api_error","message":"internal"}}`, ErrAPI, true},
+		// bodies that are not Anthropic errors are classified by status
+		{"proxy 429", 429, "Too Many Requests", ErrRateLimit, true},
+		{"proxy 529", 529, "<html>overloaded</html>", ErrOverloaded, true},
+		{"gateway", 502, "<html>Bad Gateway</html>", ErrAPI, true},
+		{"wrong base URL", 404, "404 page not found", ErrNotFound, false},
+		{"proxy 400", 400, "bad request", ErrInvalidRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, AnthropicConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			_, err := c.PromptWithContext(context.Background(), "hi", nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Type != tt.wantType || apiErr.Message == "" {
+				t.Errorf("got %+v, want status %d type %s", apiErr, tt.status, tt.wantType)
+			}
+			if apiErr.Retryable() != tt.retryable {
+				t.Errorf("Retryable = %v, want %v", apiErr.Retryable(), tt.retryable)
+			}
+		})
+	}
+}
+
+type stubEmbedder struct{}
+
+func (stubEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	return []float64{1, 2}, nil
+}
+
+func (stubEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	return nil, nil
+}
+
+func (stubEmbedder) Dimensions() int { return 2 }
+
+func TestEmbedder(t *testing.T) {
+	c := NewClient("key")
+	if _, err := c.Embed(context.Background(), "x"); !errors.Is(err, ErrNoEmbedder) {
+		t.Errorf("Embed without an embedder = %v", err)
+	}
+	c.SetEmbedder(stubEmbedder{})
+	if v, err := c.Embed(context.Background(), "x"); err != nil || len(v) != 2 {
+		t.Errorf("Embed = %v, %v", v, err)
+	}
+	if _, err := New(AnthropicConfig{}); err == nil {
+		t.Error("New accepted a config without APIKey")
+	}
+}
@@ -0,0 +1,103 @@
+package anthropic
+
+import (
+	"fmt"
+	"net/http"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+type Client struct {
+	apiKey     string
+	baseURL    string
+	version    string
+	model      string
+	maxTokens  int
+	maxContext int
+	embedder   llmproviders.Embedder
+	httpClient *http.Client
+}
+
+// AnthropicConfig holds config for the Anthropic client
+type AnthropicConfig struct {
+	APIKey     string
+	BaseURL    string // defaults to https://api.anthropic.com/v1
+	Version    string // anthropic-version header, defaults to 2023-06-01
+	Model      string // defaults to DefaultModel
+	MaxTokens  int    // answer length limit, defaults to 1024
+	MaxContext int    // context window in tokens, defaults to 200000
+	// Embedder is optional and used by Embed. Anthropic has no embeddings endpoint, so
+	// pair the client with another provider (e.g. an openai.Client), or give the
+	// Prompter a separate Embedder and use this client only as its Generator.
+	Embedder llmproviders.Embedder
+}
+
+// Typed prompt options, passed through PromptWithContext's opts.
+type (
+	// Model overrides the configured model for one request.
+	Model string
+	// Temperature sets the sampling temperature (0-1).
+	Temperature float64
+	// MaxTokens overrides the configured answer length limit for one request.
+	MaxTokens int
+)
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type messagesRequest struct {
+	Model       string    `json:"model"`
+	MaxTokens   int       `json:"max_tokens"`
+	System      string    `json:"system,omitempty"`
+	Messages    []message `json:"messages"`
+	Temperature *float64  `json:"temperature,omitempty"`
+}
+
+type messagesResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Anthropic error types, as returned in APIError.Type.
+const (
+	ErrInvalidRequest  = "invalid_request_error"
+	ErrAuthentication  = "authentication_error"
+	ErrPermission      = "permission_error"
+	ErrNotFound        = "not_found_error"
+	ErrRequestTooLarge = "request_too_large"
+	ErrRateLimit       = "rate_limit_error"
+	ErrAPI             = "api_error"
+	ErrOverloaded      = "overloaded_error"
+)
+
+// APIError is an error response from the Anthropic API.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("anthropic API error %d (%s): %s", e.StatusCode, e.Type, e.Message)
+}
+
+// HTTPStatus returns the HTTP status code of the response.
+func (e *APIError) HTTPStatus() int { return e.StatusCode }
+
+// Retryable reports whether the request may succeed if sent again later.
+func (e *APIError) Retryable() bool {
+	switch e.Type {
+	case ErrRateLimit, ErrOverloaded, ErrAPI:
+		return true
+	}
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
@@ -0,0 +1,28 @@
+package llmproviders
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HTTPStatusError is implemented by provider errors for a failed HTTP call.
+type HTTPStatusError interface {
+	error
+	HTTPStatus() int
+}
+
+// IsInputError reports whether err is the provider rejecting the request content
+// (HTTP 400, 413 or 422), such as a text over the model's input limit, rather than
+// the call failing as a whole (auth, rate limits, outages). For a batch it means
+// some input was bad, so sending the texts one at a time can isolate it.
+func IsInputError(err error) bool {
+	var se HTTPStatusError
+	if !errors.As(err, &se) {
+		return false
+	}
+	switch se.HTTPStatus() {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 
 	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+	"github.com/shreetheja/ai-contextual-prompter/llm-providers/anthropic"
+	"github.com/shreetheja/ai-contextual-prompter/llm-providers/gemini"
+	"github.com/shreetheja/ai-contextual-prompter/llm-providers/ollama"
 	"github.com/shreetheja/ai-contextual-prompter/llm-providers/openai"
 )
 
@@ -19,7 +22,13 @@ func NewLLM(provider string, cfg ...llmproviders.PromptOption) (llmproviders.LLM
 	switch provider {
 	case "openai":
 		return openai.New(cfg...)
-	// Add more providers here (e.g., "claude", "gemini")
+	case llmproviders.ANTHROPIC:
+		return anthropic.New(cfg...)
+	case llmproviders.OLLAMA:
+		return ollama.New(cfg...)
+	case llmproviders.GEMINI:
+		return gemini.New(cfg...)
+	// Add more providers here
 	default:
 		return nil, fmt.Errorf("unknown llm provider: %s", provider)
 	}
@@ -0,0 +1,289 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// Google Gemini provider on top of the generateContent and embedContent REST
+// endpoints. Retrieved context is sent as the system instruction.
+
+const (
+	geminiBase        = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultModel      = "gemini-2.0-flash"
+	DefaultEmbedModel = "text-embedding-004"
+	fallbackContext   = 32768
+)
+
+// modelContext lists input token limits by model name prefix, longest first.
+var modelContext = []struct {
+	prefix string
+	tokens int
+}{
+	{"gemini-1.5-pro", 2097152},
+	{"gemini-1.5-flash", 1048576},
+	{"gemini-2.0-flash", 1048576},
+	{"gemini-2.5-pro", 1048576},
+	{"gemini-2.5-flash", 1048576},
+	{"gemini-1.0-pro", 30720},
+}
+
+var embeddingDims = map[string]int{
+	"text-embedding-004": 768,
+	"embedding-001":      768,
+}
+
+// NewClient creates a new Gemini client with default models.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    geminiBase,
+		model:      DefaultModel,
+		embedModel: DefaultEmbedModel,
+		maxContext: contextFor(DefaultModel),
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+func New(cfg ...llmproviders.PromptOption) (*Client, error) {
+	if len(cfg) == 0 {
+		return nil, fmt.Errorf("no config provided for gemini")
+	}
+	c, ok := cfg[0].(GeminiConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid config for gemini")
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	client := NewClient(c.APIKey)
+	if c.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(c.BaseURL, "/")
+	}
+	if c.Model != "" {
+		client.model = c.Model
+		client.maxContext = contextFor(c.Model)
+	}
+	if c.EmbedModel != "" {
+		client.embedModel = c.EmbedModel
+	}
+	if c.MaxContext > 0 {
+		client.maxContext = c.MaxContext
+	}
+	client.maxOutputTokens = c.MaxOutputTokens
+	client.safety = c.SafetySettings
+	return client, nil
+}
+
+// Validate checks the config before a client is built.
+func (c GeminiConfig) Validate() error {
+	if c.APIKey == "" {
+		return fmt.Errorf("gemini: APIKey is required")
+	}
+	if c.MaxContext < 0 || c.MaxOutputTokens < 0 {
+		return fmt.Errorf("gemini: token limits must not be negative")
+	}
+	for _, s := range c.SafetySettings {
+		if !harmCategories[s.Category] {
+			return fmt.Errorf("gemini: unknown safety category %q", s.Category)
+		}
+		if !harmThresholds[s.Threshold] {
+			return fmt.Errorf("gemini: unknown safety threshold %q", s.Threshold)
+		}
+	}
+	return nil
+}
+
+func contextFor(model string) int {
+	model = strings.TrimPrefix(model, "models/")
+	for _, m := range modelContext {
+		if strings.HasPrefix(model, m.prefix) {
+			return m.tokens
+		}
+	}
+	return fallbackContext
+}
+
+// Name returns the name of the LLM provider.
+func (c *Client) Name() string {
+	return "gemini"
+}
+
+// MaxContext returns the input token limit of the configured model.
+func (c *Client) MaxContext() int {
+	return c.maxContext
+}
+
+// Embed returns the embedding vector for text using embedContent.
+func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
+	body, err := c.post(ctx, modelPath(c.embedModel)+":embedContent", c.embedRequest(text))
+	if err != nil {
+		return nil, err
+	}
+	var out embedResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embedding.Values) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+	c.learnDims(out.Embedding.Values)
+	return out.Embedding.Values, nil
+}
+
+// EmbedBatch embeds all texts with one batchEmbedContents call, in input order.
+func (c *Client) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	reqBody := batchEmbedRequest{Requests: make([]embedRequest, len(texts))}
+	for i, t := range texts {
+		reqBody.Requests[i] = c.embedRequest(t)
+	}
+	body, err := c.post(ctx, modelPath(c.embedModel)+":batchEmbedContents", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	var out batchEmbedResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(out.Embeddings))
+	}
+	vecs := make([][]float64, len(texts))
+	for i, e := range out.Embeddings {
+		vecs[i] = e.Values
+	}
+	c.learnDims(vecs[0])
+	return vecs, nil
+}
+
+// Dimensions returns the vector length of the embedding model, or 0 if unknown
+// before the first embedding call.
+func (c *Client) Dimensions() int {
+	if d := atomic.LoadInt64(&c.dims); d > 0 {
+		return int(d)
+	}
+	return embeddingDims[strings.TrimPrefix(c.embedModel, "models/")]
+}
+
+func (c *Client) learnDims(vec []float64) {
+	atomic.StoreInt64(&c.dims, int64(len(vec)))
+}
+
+func (c *Client) embedRequest(text string) embedRequest {
+	return embedRequest{
+		Model:   modelPath(c.embedModel),
+		Content: content{Parts: []part{{Text: text}}},
+	}
+}
+
+// PromptWithContext sends prompt as user content with the context items as the
+// system instruction. Accepts Model, Temperature and MaxOutputTokens options.
+func (c *Client) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (string, error) {
+	model := c.model
+	gen := generationConfig{MaxOutputTokens: c.maxOutputTokens}
+	for _, o := range opts {
+		switch v := o.(type) {
+		case Model:
+			model = string(v)
+		case Temperature:
+			t := float64(v)
+			gen.Temperature = &t
+		case MaxOutputTokens:
+			gen.MaxOutputTokens = int(v)
+		}
+	}
+	reqBody := generateRequest{
+		Contents:       []content{{Role: "user", Parts: []part{{Text: prompt}}}},
+		SafetySettings: c.safety,
+	}
+	if len(contextItems) > 0 {
+		reqBody.SystemInstruction = &content{Parts: []part{{Text: strings.Join(contextItems, "\n\n")}}}
+	}
+	if gen.Temperature != nil || gen.MaxOutputTokens > 0 {
+		reqBody.GenerationConfig = &gen
+	}
+
+	body, err := c.post(ctx, modelPath(model)+":generateContent", reqBody)
+	if err != nil {
+		return "", err
+	}
+	var out generateResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", err
+	}
+	if out.PromptFeedback.BlockReason != "" {
+		return "", &BlockedError{Reason: out.PromptFeedback.BlockReason}
+	}
+	if len(out.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates returned")
+	}
+	cand := out.Candidates[0]
+	var sb strings.Builder
+	for _, p := range cand.Content.Parts {
+		sb.WriteString(p.Text)
+	}
+	if sb.Len() == 0 && (cand.FinishReason == "SAFETY" || cand.FinishReason == "BLOCKLIST" || cand.FinishReason == "PROHIBITED_CONTENT") {
+		return "", &BlockedError{Reason: cand.FinishReason}
+	}
+	return sb.String(), nil
+}
+
+func modelPath(model string) string {
+	if strings.HasPrefix(model, "models/") {
+		return model
+	}
+	return "models/" + model
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, data interface{}) ([]byte, error) {
+	jsonData, _ := json.Marshal(data)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.baseURL, endpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		var env struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		if json.Unmarshal(body, &env) == nil && env.Error.Message != "" {
+			apiErr.Status = env.Error.Status
+			apiErr.Message = env.Error.Message
+		}
+		return nil, apiErr
+	}
+	return body, nil
+}
+
+var (
+	_ llmproviders.LLM       = &Client{}
+	_ llmproviders.Embedder  = &Client{}
+	_ llmproviders.Generator = &Client{}
+)
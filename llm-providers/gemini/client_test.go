@@ -0,0 +1,227 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testClient returns a client pointed at a test server running handler.
+func testClient(t *testing.T, cfg GeminiConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	if cfg.APIKey == "" {
+		cfg.APIKey = "key"
+	}
+	cfg.BaseURL = srv.URL + "/"
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestConfigValidate(t *testing.T) {
+	ok := SafetySetting{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_ONLY_HIGH"}
+	tests := []struct {
+		name    string
+		cfg     GeminiConfig
+		wantErr string
+	}{
+		{"valid", GeminiConfig{APIKey: "k", SafetySettings: []SafetySetting{ok}}, ""},
+		{"no key", GeminiConfig{}, "APIKey"},
+		{"negative context", GeminiConfig{APIKey: "k", MaxContext: -1}, "negative"},
+		{"negative output", GeminiConfig{APIKey: "k", MaxOutputTokens: -1}, "negative"},
+		{"bad category", GeminiConfig{APIKey: "k", SafetySettings: []SafetySetting{{Category: "HARM_CATEGORY_X", Threshold: "OFF"}}}, "category"},
+		{"bad threshold", GeminiConfig{APIKey: "k", SafetySettings: []SafetySetting{{Category: ok.Category, Threshold: "SOMETIMES"}}}, "threshold"},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Validate() = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+	if _, err := New(GeminiConfig{}); err == nil {
+		t.Error("New accepted an invalid config")
+	}
+}
+
+func TestGenerateContent(t *testing.T) {
+	safety := []SafetySetting{{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_NONE"}}
+	var got generateRequest
+	c := testClient(t, GeminiConfig{Model: "gemini-1.5-pro", MaxOutputTokens: 100, SafetySettings: safety}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-1.5-flash:generateContent" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.Header.Get("x-goog-api-key") != "key" {
+			t.Errorf("missing API key header")
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"},{"text":" world"}]},"finishReason":"STOP"}]}`)
+	})
+	if c.MaxContext() != 2097152 {
+		t.Errorf("MaxContext = %d", c.MaxContext())
+	}
+	answer, err := c.PromptWithContext(context.Background(), "hi", []string{"a", "b"}, Model("gemini-1.5-flash"), Temperature(0.2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Hello world" {
+		t.Errorf("answer = %q", answer)
+	}
+	if got.SystemInstruction == nil || got.SystemInstruction.Parts[0].Text != "a\n\nb" {
+		t.Errorf("system instruction = %+v", got.SystemInstruction)
+	}
+	if len(got.Contents) != 1 || got.Contents[0].Role != "user" || got.Contents[0].Parts[0].Text != "hi" {
+		t.Errorf("contents = %+v", got.Contents)
+	}
+	if len(got.SafetySettings) != 1 || got.SafetySettings[0] != safety[0] {
+		t.Errorf("safety settings = %+v", got.SafetySettings)
+	}
+	if g := got.GenerationConfig; g == nil || g.Temperature == nil || *g.Temperature != 0.2 || g.MaxOutputTokens != 100 {
+		t.Errorf("generation config = %+v", g)
+	}
+}
+
+func TestGenerateContentNoContext(t *testing.T) {
+	c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&raw)
+		for _, key := range []string{"systemInstruction", "safetySettings", "generationConfig"} {
+			if _, ok := raw[key]; ok {
+				t.Errorf("%s sent without being set", key)
+			}
+		}
+		fmt.Fprint(w, `{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`)
+	})
+	if _, err := c.PromptWithContext(context.Background(), "hi", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateContentBlocked(t *testing.T) {
+	tests := []struct {
+		name, body, reason string
+	}{
+		{"prompt blocked", `{"promptFeedback":{"blockReason":"SAFETY"}}`, "SAFETY"},
+		{"answer blocked", `{"candidates":[{"content":{"parts":[]},"finishReason":"PROHIBITED_CONTENT"}]}`, "PROHIBITED_CONTENT"},
+	}
+	for _, tt := range tests {
+		c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, tt.body)
+		})
+		_, err := c.PromptWithContext(context.Background(), "hi", nil)
+		var blocked *BlockedError
+		if !errors.As(err, &blocked) || blocked.Reason != tt.reason {
+			t.Errorf("%s: err = %v, want BlockedError %s", tt.name, err, tt.reason)
+		}
+	}
+
+	c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"candidates":[]}`)
+	})
+	if _, err := c.PromptWithContext(context.Background(), "hi", nil); err == nil || !strings.Contains(err.Error(), "no candidates") {
+		t.Errorf("empty candidates: err = %v", err)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/text-embedding-004:embedContent" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var req embedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "models/text-embedding-004" || req.Content.Parts[0].Text != "hello" {
+			t.Errorf("request = %+v", req)
+		}
+		fmt.Fprint(w, `{"embedding":{"values":[0.1,0.2,0.3]}}`)
+	})
+	if c.Dimensions() != 768 {
+		t.Errorf("Dimensions before embedding = %d", c.Dimensions())
+	}
+	vec, err := c.Embed(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vec) != 3 || vec[2] != 0.3 {
+		t.Errorf("vec = %v", vec)
+	}
+	if c.Dimensions() != 3 {
+		t.Errorf("Dimensions after embedding = %d", c.Dimensions())
+	}
+}
+
+func TestEmbedBatch(t *testing.T) {
+	c := testClient(t, GeminiConfig{EmbedModel: "models/embedding-001"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/embedding-001:batchEmbedContents" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		var req batchEmbedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		out := batchEmbedResponse{}
+		for i, q := range req.Requests {
+			if q.Model != "models/embedding-001" {
+				t.Errorf("request model = %s", q.Model)
+			}
+			out.Embeddings = append(out.Embeddings, struct {
+				Values []float64 `json:"values"`
+			}{[]float64{float64(i), float64(len(q.Content.Parts[0].Text))}})
+		}
+		json.NewEncoder(w).Encode(out)
+	})
+	vecs, err := c.EmbedBatch(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range vecs {
+		if v[0] != float64(i) || v[1] != float64(i+1) {
+			t.Errorf("vecs[%d] = %v, out of order", i, v)
+		}
+	}
+	if vecs, err := c.EmbedBatch(context.Background(), nil); err != nil || vecs != nil {
+		t.Errorf("empty batch = %v, %v", vecs, err)
+	}
+}
+
+func TestEmbedBatchCountMismatch(t *testing.T) {
+	c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"embeddings":[{"values":[1]}]}`)
+	})
+	if _, err := c.EmbedBatch(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("expected an error for a short batch response")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		status         int
+		body           string
+		wantStatus     string
+		wantMessageSub string
+	}{
+		{http.StatusBadRequest, `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`, "INVALID_ARGUMENT", "API key not valid"},
+		{http.StatusTooManyRequests, `{"error":{"code":429,"message":"quota","status":"RESOURCE_EXHAUSTED"}}`, "RESOURCE_EXHAUSTED", "quota"},
+		{http.StatusBadGateway, `<html>bad gateway</html>`, "", "bad gateway"},
+	}
+	for _, tt := range tests {
+		c := testClient(t, GeminiConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			fmt.Fprint(w, tt.body)
+		})
+		_, err := c.Embed(context.Background(), "x")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("status %d: err = %v, want *APIError", tt.status, err)
+		}
+		if apiErr.StatusCode != tt.status || apiErr.Status != tt.wantStatus || !strings.Contains(apiErr.Message, tt.wantMessageSub) {
+			t.Errorf("status %d: got %+v", tt.status, apiErr)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gemini
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Client struct {
+	apiKey          string
+	baseURL         string
+	model           string
+	embedModel      string
+	maxContext      int
+	maxOutputTokens int
+	safety          []SafetySetting
+	dims            int64 // learned from the first embedding response
+	httpClient      *http.Client
+}
+
+// GeminiConfig holds config for the Gemini client
+type GeminiConfig struct {
+	APIKey          string
+	BaseURL         string // defaults to https://generativelanguage.googleapis.com/v1beta
+	Model           string // defaults to DefaultModel
+	EmbedModel      string // defaults to DefaultEmbedModel
+	MaxContext      int    // input token limit; derived from Model when 0
+	MaxOutputTokens int    // optional answer length limit
+	SafetySettings  []SafetySetting
+}
+
+// SafetySetting sets the blocking threshold for one harm category, e.g.
+// {Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_ONLY_HIGH"}.
+type SafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+var harmCategories = map[string]bool{
+	"HARM_CATEGORY_HARASSMENT":        true,
+	"HARM_CATEGORY_HATE_SPEECH":       true,
+	"HARM_CATEGORY_SEXUALLY_EXPLICIT": true,
+	"HARM_CATEGORY_DANGEROUS_CONTENT": true,
+	"HARM_CATEGORY_CIVIC_INTEGRITY":   true,
+}
+
+var harmThresholds = map[string]bool{
+	"BLOCK_NONE":             true,
+	"BLOCK_ONLY_HIGH":        true,
+	"BLOCK_MEDIUM_AND_ABOVE": true,
+	"BLOCK_LOW_AND_ABOVE":    true,
+	"OFF":                    true,
+}
+
+// Typed prompt options, passed through PromptWithContext's opts.
+type (
+	// Model overrides the configured model for one request.
+	Model string
+	// Temperature sets the sampling temperature.
+	Temperature float64
+	// MaxOutputTokens overrides the answer length limit for one request.
+	MaxOutputTokens int
+)
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []part `json:"parts"`
+}
+
+type generationConfig struct {
+	Temperature     *float64 `json:"temperature,omitempty"`
+	MaxOutputTokens int      `json:"maxOutputTokens,omitempty"`
+}
+
+type generateRequest struct {
+	SystemInstruction *content          `json:"systemInstruction,omitempty"`
+	Contents          []content         `json:"contents"`
+	SafetySettings    []SafetySetting   `json:"safetySettings,omitempty"`
+	GenerationConfig  *generationConfig `json:"generationConfig,omitempty"`
+}
+
+type generateResponse struct {
+	Candidates []struct {
+		Content      content `json:"content"`
+		FinishReason string  `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+}
+
+type embedRequest struct {
+	Model   string  `json:"model"`
+	Content content `json:"content"`
+}
+
+type embedResponse struct {
+	Embedding struct {
+		Values []float64 `json:"values"`
+	} `json:"embedding"`
+}
+
+type batchEmbedRequest struct {
+	Requests []embedRequest `json:"requests"`
+}
+
+type batchEmbedResponse struct {
+	Embeddings []struct {
+		Values []float64 `json:"values"`
+	} `json:"embeddings"`
+}
+
+// APIError is an error response from the Gemini API.
+type APIError struct {
+	StatusCode int
+	Status     string // e.g. INVALID_ARGUMENT, RESOURCE_EXHAUSTED
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gemini API error %d (%s): %s", e.StatusCode, e.Status, e.Message)
+}
+
+// HTTPStatus returns the HTTP status code of the response.
+func (e *APIError) HTTPStatus() int { return e.StatusCode }
+
+// BlockedError is returned when Gemini's safety filters block the prompt or answer.
+type BlockedError struct {
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	return "gemini blocked the response: " + e.Reason
+}
@@ -0,0 +1,181 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// Ollama provider for local models: chat through /api/chat and embeddings through
+// /api/embed, so the whole prompter flow can run offline.
+
+const (
+	ollamaBase           = "http://localhost:11434"
+	DefaultModel         = "llama3.1"
+	DefaultEmbedModel    = "nomic-embed-text"
+	defaultContextWindow = 4096
+)
+
+// NewClient creates a new Ollama client against baseURL with default models.
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = ollamaBase
+	}
+	return &Client{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		model:         DefaultModel,
+		embedModel:    DefaultEmbedModel,
+		contextWindow: defaultContextWindow,
+		// local models can be slow to load on first use
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func New(cfg ...llmproviders.PromptOption) (*Client, error) {
+	c := OllamaConfig{}
+	if len(cfg) > 0 {
+		var ok bool
+		c, ok = cfg[0].(OllamaConfig)
+		if !ok {
+			return nil, fmt.Errorf("invalid config for ollama")
+		}
+	}
+	if c.ContextWindow < 0 {
+		return nil, fmt.Errorf("ollama: ContextWindow must not be negative")
+	}
+	client := NewClient(c.BaseURL)
+	if c.Model != "" {
+		client.model = c.Model
+	}
+	if c.EmbedModel != "" {
+		client.embedModel = c.EmbedModel
+	}
+	if c.ContextWindow > 0 {
+		client.contextWindow = c.ContextWindow
+	}
+	return client, nil
+}
+
+// Name returns the name of the LLM provider.
+func (c *Client) Name() string {
+	return "ollama"
+}
+
+// MaxContext returns the configured context window (num_ctx) in tokens.
+func (c *Client) MaxContext() int {
+	return c.contextWindow
+}
+
+// Embed returns the embedding vector for text using the configured embedding model.
+func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
+	out, err := c.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// EmbedBatch embeds all texts in one /api/embed call, in input order.
+func (c *Client) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := c.post(ctx, "api/embed", embedRequest{Model: c.embedModel, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	var out embedResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(out.Embeddings))
+	}
+	atomic.StoreInt64(&c.dims, int64(len(out.Embeddings[0])))
+	return out.Embeddings, nil
+}
+
+// Dimensions returns the vector length seen from the embedding model, or 0 before
+// the first embedding call.
+func (c *Client) Dimensions() int {
+	return int(atomic.LoadInt64(&c.dims))
+}
+
+// PromptWithContext sends the context items as one system message followed by the
+// prompt. Accepts Model, Temperature and Options.
+func (c *Client) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (string, error) {
+	reqBody := chatRequest{
+		Model:   c.model,
+		Stream:  false,
+		Options: map[string]interface{}{"num_ctx": c.contextWindow},
+	}
+	for _, o := range opts {
+		switch v := o.(type) {
+		case Model:
+			reqBody.Model = string(v)
+		case Temperature:
+			reqBody.Options["temperature"] = float64(v)
+		case Options:
+			for k, val := range v {
+				reqBody.Options[k] = val
+			}
+		}
+	}
+	if len(contextItems) > 0 {
+		reqBody.Messages = append(reqBody.Messages, chatMessage{Role: "system", Content: strings.Join(contextItems, "\n\n")})
+	}
+	reqBody.Messages = append(reqBody.Messages, chatMessage{Role: "user", Content: prompt})
+
+	body, err := c.post(ctx, "api/chat", reqBody)
+	if err != nil {
+		return "", err
+	}
+	var out chatResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", err
+	}
+	return out.Message.Content, nil
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, data interface{}) ([]byte, error) {
+	jsonData, _ := json.Marshal(data)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.baseURL, endpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: apiErr.Error}
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+	return body, nil
+}
+
+var (
+	_ llmproviders.LLM       = &Client{}
+	_ llmproviders.Embedder  = &Client{}
+	_ llmproviders.Generator = &Client{}
+)
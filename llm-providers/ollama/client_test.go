@@ -0,0 +1,161 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testClient returns a client pointed at a test server running handler.
+func testClient(t *testing.T, cfg OllamaConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg.BaseURL = srv.URL + "/"
+	c, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestChat(t *testing.T) {
+	var got chatRequest
+	c := testClient(t, OllamaConfig{Model: "mistral", ContextWindow: 8192}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		got = chatRequest{}
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"model":"mistral","message":{"role":"assistant","content":"Paris"},"done":true,"prompt_eval_count":12,"eval_count":2}`)
+	})
+	if c.MaxContext() != 8192 {
+		t.Errorf("MaxContext = %d", c.MaxContext())
+	}
+	answer, err := c.PromptWithContext(context.Background(), "capital of France?", []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if answer != "Paris" {
+		t.Errorf("answer = %q", answer)
+	}
+	if got.Model != "mistral" || got.Stream {
+		t.Errorf("model %q, stream %v", got.Model, got.Stream)
+	}
+	want := []chatMessage{{Role: "system", Content: "a\n\nb"}, {Role: "user", Content: "capital of France?"}}
+	if len(got.Messages) != 2 || got.Messages[0] != want[0] || got.Messages[1] != want[1] {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if got.Options["num_ctx"] != 8192.0 {
+		t.Errorf("num_ctx = %v", got.Options["num_ctx"])
+	}
+
+	// no context: no system message
+	if _, err := c.PromptWithContext(context.Background(), "hi", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+}
+
+func TestChatOptions(t *testing.T) {
+	var got chatRequest
+	c := testClient(t, OllamaConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"ok"},"done":true}`)
+	})
+	_, err := c.PromptWithContext(context.Background(), "hi", nil,
+		Model("qwen2"), Temperature(0.1), Options{"num_predict": 64, "seed": 7, "num_ctx": 2048})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "qwen2" {
+		t.Errorf("model = %q", got.Model)
+	}
+	want := map[string]interface{}{"temperature": 0.1, "num_predict": 64.0, "seed": 7.0, "num_ctx": 2048.0}
+	for k, v := range want {
+		if got.Options[k] != v {
+			t.Errorf("option %s = %v, want %v", k, got.Options[k], v)
+		}
+	}
+	if c.MaxContext() != defaultContextWindow {
+		t.Errorf("MaxContext = %d, want the default", c.MaxContext())
+	}
+}
+
+func TestEmbedBatch(t *testing.T) {
+	var got embedRequest
+	c := testClient(t, OllamaConfig{EmbedModel: "mxbai-embed-large"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		vecs := make([]string, len(got.Input))
+		for i := range got.Input {
+			vecs[i] = fmt.Sprintf("[%d, 0.5, 1]", i)
+		}
+		fmt.Fprintf(w, `{"model":"mxbai-embed-large","embeddings":[%s]}`, strings.Join(vecs, ","))
+	})
+	if c.Dimensions() != 0 {
+		t.Errorf("Dimensions before embedding = %d", c.Dimensions())
+	}
+	vecs, err := c.EmbedBatch(context.Background(), []string{"one", "two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "mxbai-embed-large" || len(got.Input) != 2 || got.Input[1] != "two" {
+		t.Errorf("request = %+v", got)
+	}
+	if len(vecs) != 2 || vecs[1][0] != 1 || c.Dimensions() != 3 {
+		t.Errorf("vecs = %v, dims %d", vecs, c.Dimensions())
+	}
+	if v, err := c.Embed(context.Background(), "solo"); err != nil || len(v) != 3 {
+		t.Errorf("Embed = %v, %v", v, err)
+	}
+	if vecs, err := c.EmbedBatch(context.Background(), nil); vecs != nil || err != nil {
+		t.Errorf("empty batch = %v, %v", vecs, err)
+	}
+}
+
+func TestEmbedCountMismatch(t *testing.T) {
+	c := testClient(t, OllamaConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"embeddings":[[1,2]]}`)
+	})
+	if _, err := c.EmbedBatch(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("one embedding for two inputs accepted")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"ollama error", 404, `{"error":"model \"llama9\" not found, try pulling it first"}`, `ollama error 404: model "llama9" not found`},
+		{"plain body", 502, "Bad Gateway", "ollama error 502: Bad Gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testClient(t, OllamaConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			if _, err := c.PromptWithContext(context.Background(), "hi", nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("chat err = %v, want %q", err, tt.want)
+			}
+			if _, err := c.EmbedBatch(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("embed err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := New(OllamaConfig{ContextWindow: -1}); err == nil {
+		t.Error("negative ContextWindow accepted")
+	}
+}
@@ -0,0 +1,75 @@
+package ollama
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Client struct {
+	baseURL       string
+	model         string
+	embedModel    string
+	contextWindow int
+	dims          int64 // learned from the first embedding response
+	httpClient    *http.Client
+}
+
+// OllamaConfig holds config for the Ollama client
+type OllamaConfig struct {
+	BaseURL       string // defaults to http://localhost:11434
+	Model         string // chat model, defaults to DefaultModel
+	EmbedModel    string // embedding model, defaults to DefaultEmbedModel
+	ContextWindow int    // num_ctx sent with every chat request, defaults to 4096
+}
+
+// Typed prompt options, passed through PromptWithContext's opts.
+type (
+	// Model overrides the configured chat model for one request.
+	Model string
+	// Temperature sets the sampling temperature.
+	Temperature float64
+	// Options are passed through as Ollama model options (num_predict, top_p, seed,
+	// ...) and override the client's, including num_ctx.
+	Options map[string]interface{}
+)
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []chatMessage          `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+type chatResponse struct {
+	Message         chatMessage `json:"message"`
+	Done            bool        `json:"done"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+	EvalCount       int         `json:"eval_count"`
+}
+
+type embedRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+// APIError is an error response from the Ollama server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ollama error %d: %s", e.StatusCode, e.Message)
+}
+
+// HTTPStatus returns the HTTP status code of the response.
+func (e *APIError) HTTPStatus() int { return e.StatusCode }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
@@ -16,9 +17,16 @@ import (
 // This package provides a unified interface for both modes.
 
 const (
-	openAiBase = "https://api.openai.com/v1"
+	openAiBase        = "https://api.openai.com/v1"
+	defaultEmbedModel = "text-embedding-ada-002"
 )
 
+var embeddingDims = map[string]int{
+	"text-embedding-ada-002": 1536,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+}
+
 // NewClient creates a new OpenAI client. If asstId is empty, classic mode is used.
 func NewClient(secKey, orgId string, asstId *string) *Client {
 	return &Client{
@@ -38,7 +46,12 @@ func New(cfg ...llmproviders.PromptOption) (*Client, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid config for openai")
 	}
-	return NewClient(c.SecKey, c.OrgId, c.AsstId), nil
+	client := NewClient(c.SecKey, c.OrgId, c.AsstId)
+	if c.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(c.BaseURL, "/")
+	}
+	client.embedModel = c.EmbedModel
+	return client, nil
 }
 
 // =====================
@@ -60,9 +73,17 @@ func (c *Client) AddMessage(ctx context.Context, threadID string, msg MessageReq
 }
 
 func (c *Client) CreateRun(ctx context.Context, threadID, assistantID string) (Run, error) {
-	resp, err := c.post(ctx, fmt.Sprintf("threads/%s/runs", threadID), map[string]string{
-		"assistant_id": assistantID,
-	})
+	return c.CreateRunWithInstructions(ctx, threadID, assistantID, "")
+}
+
+// CreateRunWithInstructions starts a run whose additional_instructions are appended
+// to the assistant's own instructions for this run only.
+func (c *Client) CreateRunWithInstructions(ctx context.Context, threadID, assistantID, instructions string) (Run, error) {
+	body := map[string]string{"assistant_id": assistantID}
+	if instructions != "" {
+		body["additional_instructions"] = instructions
+	}
+	resp, err := c.post(ctx, fmt.Sprintf("threads/%s/runs", threadID), body)
 	if err != nil {
 		return Run{}, err
 	}
@@ -108,19 +129,33 @@ func (c *Client) ListMessages(ctx context.Context, threadID string) ([]Message,
 
 // Embed returns the embedding vector for a given text using OpenAI's embedding API.
 func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
+	out, err := c.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// EmbedBatch embeds all texts in a single /embeddings request and returns the vectors
+// in input order.
+func (c *Client) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
 	type embedReq struct {
 		Model string   `json:"model"`
 		Input []string `json:"input"`
 	}
 	type embedResp struct {
 		Data []struct {
+			Index     int       `json:"index"`
 			Embedding []float64 `json:"embedding"`
 		} `json:"data"`
 	}
+	if len(texts) == 0 {
+		return nil, nil
+	}
 
 	reqBody := embedReq{
-		Model: "text-embedding-ada-002", // or configurable
-		Input: []string{text},
+		Model: c.embeddingModel(),
+		Input: texts,
 	}
 
 	jsonData, _ := json.Marshal(reqBody)
@@ -139,17 +174,37 @@ func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	body, _ := io.ReadAll(resp.Body)
 	var out embedResp
 	if err := json.Unmarshal(body, &out); err != nil {
 		return nil, err
 	}
-	if len(out.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
+	if len(out.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(out.Data))
 	}
-	return out.Data[0].Embedding, nil
+	vecs := make([][]float64, len(texts))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vecs) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vecs[d.Index] = d.Embedding
+	}
+	return vecs, nil
+}
+
+// Dimensions returns the vector length of the configured embedding model, or 0 if
+// the model is not known.
+func (c *Client) Dimensions() int {
+	return embeddingDims[c.embeddingModel()]
+}
+
+func (c *Client) embeddingModel() string {
+	if c.embedModel != "" {
+		return c.embedModel
+	}
+	return defaultEmbedModel
 }
 
 // MaxContext returns the max context tokens for the model (hardcoded for now)
@@ -176,16 +231,9 @@ func (c *Client) PromptClassic(ctx context.Context, prompt string, contextItems
 		} `json:"choices"`
 	}
 
-	// Build messages array: context as system/user, then prompt as user
-	var messages []interface{}
-	for _, ctxItem := range contextItems {
-		messages = append(messages, map[string]string{"role": "system", "content": ctxItem})
-	}
-	messages = append(messages, map[string]string{"role": "user", "content": prompt})
-
 	reqBody := chatReq{
 		Model:    "gpt-3.5-turbo", // or configurable
-		Messages: messages,
+		Messages: chatMessages(prompt, contextItems),
 	}
 
 	jsonData, _ := json.Marshal(reqBody)
@@ -204,7 +252,7 @@ func (c *Client) PromptClassic(ctx context.Context, prompt string, contextItems
 	defer resp.Body.Close()
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	body, _ := io.ReadAll(resp.Body)
 	var out chatResp
@@ -217,23 +265,27 @@ func (c *Client) PromptClassic(ctx context.Context, prompt string, contextItems
 	return out.Choices[0].Message.Content, nil
 }
 
+// chatMessages builds the messages array: context joined into one system message,
+// then prompt as user.
+func chatMessages(prompt string, contextItems []string) []interface{} {
+	var messages []interface{}
+	if len(contextItems) > 0 {
+		messages = append(messages, map[string]string{"role": "system", "content": strings.Join(contextItems, "\n\n")})
+	}
+	return append(messages, map[string]string{"role": "user", "content": prompt})
+}
+
 // PromptWithContext is a unified method to prompt either Assistant API or classic API based on config.
 // If assistantID is set, uses Assistant API; otherwise, uses classic chat API.
 func (c *Client) PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (string, error) {
 	if c.assistantID != nil {
-		// Use Assistant API: expects contextItems to be a sequence of messages (role/content)
+		// Use Assistant API: context goes into the run's additional instructions,
+		// the same place the other providers put it (the system prompt)
 		thread, err := c.CreateThread(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to create thread: %w", err)
 		}
 		threadID := thread.ID
-		// Add all context messages to the thread
-		for _, ctxItem := range contextItems {
-			msg := MessageRequest{Role: "user", Content: ctxItem}
-			if err := c.AddMessage(ctx, threadID, msg); err != nil {
-				return "", fmt.Errorf("failed to add context message: %w", err)
-			}
-		}
 		// Add user prompt
 		if err := c.AddMessage(ctx, threadID, MessageRequest{Role: "user", Content: prompt}); err != nil {
 			return "", fmt.Errorf("failed to add user prompt: %w", err)
@@ -242,7 +294,7 @@ func (c *Client) PromptWithContext(ctx context.Context, prompt string, contextIt
 		if c.assistantID != nil {
 			assistant = *c.assistantID
 		}
-		run, err := c.CreateRun(ctx, threadID, assistant)
+		run, err := c.CreateRunWithInstructions(ctx, threadID, assistant, strings.Join(contextItems, "\n\n"))
 		if err != nil {
 			return "", fmt.Errorf("failed to create run: %w", err)
 		}
@@ -343,7 +395,7 @@ func (c *Client) post(ctx context.Context, endpoint string, data interface{}) ([
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return io.ReadAll(resp.Body)
@@ -378,4 +430,8 @@ func (c *Client) Name() string {
 	return "openai"
 }
 
-var _ llmproviders.LLM = &Client{}
+var (
+	_ llmproviders.LLM       = &Client{}
+	_ llmproviders.Embedder  = &Client{}
+	_ llmproviders.Generator = &Client{}
+)
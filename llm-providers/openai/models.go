@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -11,8 +12,22 @@ type Client struct {
 	baseURL     string
 	httpClient  *http.Client
 	assistantID *string
+	embedModel  string
 }
 
+// APIError is an error response from the OpenAI API.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTPStatus returns the HTTP status code of the response.
+func (e *APIError) HTTPStatus() int { return e.StatusCode }
+
 // Assistant types
 type Thread struct {
 	ID string `json:"id"`
@@ -60,4 +75,8 @@ type OpenAIConfig struct {
 	SecKey string
 	OrgId  string
 	AsstId *string // pointer: nil for classic, value for assistant
+	// BaseURL overrides https://api.openai.com/v1 (proxies, Azure-compatible gateways, tests)
+	BaseURL string
+	// EmbedModel defaults to text-embedding-ada-002
+	EmbedModel string
 }
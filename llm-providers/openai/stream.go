@@ -0,0 +1,271 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// sseEvent is one server-sent event.
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// readSSE parses a text/event-stream body and calls fn for every event. It stops at
+// the first error returned by fn.
+func readSSE(r io.Reader, fn func(sseEvent) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var ev sseEvent
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 && ev.Event == "" {
+			return nil
+		}
+		ev.Data = strings.Join(data, "\n")
+		err := fn(ev)
+		ev, data = sseEvent{}, nil
+		return err
+	}
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // comment / keep-alive
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			ev.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
+
+// errStreamDone stops readSSE once the terminal event has been seen.
+var errStreamDone = fmt.Errorf("stream done")
+
+type apiUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *apiUsage) toUsage() *llmproviders.Usage {
+	if u == nil {
+		return nil
+	}
+	return &llmproviders.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+}
+
+// PromptWithContextStream streams the answer from the classic chat API or, if an
+// assistant ID is configured, from an Assistant run.
+func (c *Client) PromptWithContextStream(ctx context.Context, prompt string, contextItems []string, opts ...llmproviders.PromptOption) (<-chan llmproviders.StreamEvent, error) {
+	if c.assistantID != nil {
+		return c.streamAssistant(ctx, prompt, contextItems)
+	}
+	return c.streamClassic(ctx, prompt, contextItems)
+}
+
+func (c *Client) streamClassic(ctx context.Context, prompt string, contextItems []string) (<-chan llmproviders.StreamEvent, error) {
+	reqBody := map[string]interface{}{
+		"model":          "gpt-3.5-turbo",
+		"messages":       chatMessages(prompt, contextItems),
+		"stream":         true,
+		"stream_options": map[string]bool{"include_usage": true},
+	}
+	resp, err := c.postStream(ctx, "chat/completions", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	type chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Usage *apiUsage `json:"usage"`
+	}
+	return c.pump(ctx, resp, func(ev sseEvent, s *streamState) error {
+		if ev.Data == "[DONE]" {
+			return errStreamDone
+		}
+		var ch chunk
+		if err := json.Unmarshal([]byte(ev.Data), &ch); err != nil {
+			return fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if ch.Usage != nil {
+			s.usage = ch.Usage.toUsage()
+		}
+		for _, choice := range ch.Choices {
+			if choice.Delta.Content != "" {
+				s.emit(choice.Delta.Content)
+			}
+		}
+		return nil
+	}), nil
+}
+
+func (c *Client) streamAssistant(ctx context.Context, prompt string, contextItems []string) (<-chan llmproviders.StreamEvent, error) {
+	thread, err := c.CreateThread(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create thread: %w", err)
+	}
+	if err := c.AddMessage(ctx, thread.ID, MessageRequest{Role: "user", Content: prompt}); err != nil {
+		return nil, fmt.Errorf("failed to add user prompt: %w", err)
+	}
+	run := map[string]interface{}{
+		"assistant_id": *c.assistantID,
+		"stream":       true,
+	}
+	if len(contextItems) > 0 {
+		run["additional_instructions"] = strings.Join(contextItems, "\n\n")
+	}
+	resp, err := c.postStream(ctx, fmt.Sprintf("threads/%s/runs", thread.ID), run)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	type messageDelta struct {
+		Delta struct {
+			Content []struct {
+				Text struct {
+					Value string `json:"value"`
+				} `json:"text"`
+			} `json:"content"`
+		} `json:"delta"`
+	}
+	type runEvent struct {
+		Status    string    `json:"status"`
+		Usage     *apiUsage `json:"usage"`
+		LastError *struct {
+			Message string `json:"message"`
+		} `json:"last_error"`
+	}
+	return c.pump(ctx, resp, func(ev sseEvent, s *streamState) error {
+		switch ev.Event {
+		case "thread.message.delta":
+			var md messageDelta
+			if err := json.Unmarshal([]byte(ev.Data), &md); err != nil {
+				return fmt.Errorf("decode message delta: %w", err)
+			}
+			for _, part := range md.Delta.Content {
+				if part.Text.Value != "" {
+					s.emit(part.Text.Value)
+				}
+			}
+		case "thread.run.completed":
+			var run runEvent
+			if err := json.Unmarshal([]byte(ev.Data), &run); err == nil {
+				s.usage = run.Usage.toUsage()
+			}
+		case "thread.run.failed", "thread.run.cancelled", "thread.run.expired":
+			var run runEvent
+			json.Unmarshal([]byte(ev.Data), &run)
+			if run.LastError != nil && run.LastError.Message != "" {
+				return fmt.Errorf("run %s: %s", run.Status, run.LastError.Message)
+			}
+			return fmt.Errorf("run %s", strings.TrimPrefix(ev.Event, "thread.run."))
+		case "error":
+			return fmt.Errorf("stream error: %s", ev.Data)
+		case "done":
+			return errStreamDone
+		}
+		return nil
+	}), nil
+}
+
+// streamState accumulates a streamed answer and forwards deltas to the caller.
+type streamState struct {
+	ctx   context.Context
+	out   chan<- llmproviders.StreamEvent
+	text  strings.Builder
+	usage *llmproviders.Usage
+}
+
+func (s *streamState) emit(delta string) {
+	s.text.WriteString(delta)
+	select {
+	case s.out <- llmproviders.StreamEvent{Delta: delta}:
+	case <-s.ctx.Done():
+	}
+}
+
+// pump reads the SSE body in a goroutine, hands every event to handle and finishes
+// with a Done event carrying the aggregated text and usage. A body that ends without
+// the terminal event finishes with io.ErrUnexpectedEOF.
+func (c *Client) pump(ctx context.Context, resp *http.Response, handle func(sseEvent, *streamState) error) <-chan llmproviders.StreamEvent {
+	out := make(chan llmproviders.StreamEvent)
+	go func() {
+		defer close(out)
+		defer resp.Body.Close()
+		s := &streamState{ctx: ctx, out: out}
+		err := readSSE(resp.Body, func(ev sseEvent) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return handle(ev, s)
+		})
+		switch err {
+		case errStreamDone:
+			err = nil
+		case nil:
+			// the body ended before [DONE], so the answer may be cut short
+			err = io.ErrUnexpectedEOF
+		}
+		final := llmproviders.StreamEvent{Done: true, Text: s.text.String(), Usage: s.usage, Err: err}
+		select {
+		case out <- final:
+		case <-ctx.Done():
+		}
+	}()
+	return out
+}
+
+// postStream posts data and returns the open response for streaming. The client's
+// overall timeout is not applied; cancellation comes from ctx.
+func (c *Client) postStream(ctx context.Context, endpoint string, data interface{}) (*http.Response, error) {
+	jsonData, _ := json.Marshal(data)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/%s", c.baseURL, endpoint), bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("OpenAI-Organization", c.orgID)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("OpenAI-Beta", "assistants=v2")
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return resp, nil
+}
+
+var _ llmproviders.StreamingLLM = &Client{}
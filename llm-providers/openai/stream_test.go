@@ -0,0 +1,208 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	llmproviders "github.com/shreetheja/ai-contextual-prompter/llm-providers"
+)
+
+// sseServer returns a classic-mode client pointed at a test server running handler.
+func sseServer(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewClient("key", "org", nil)
+	c.baseURL = srv.URL
+	return c
+}
+
+// writeEvents writes each event and flushes it, like an SSE endpoint.
+func writeEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, ev := range events {
+		fmt.Fprint(w, ev+"\n\n")
+		w.(http.Flusher).Flush()
+	}
+}
+
+// collect drains ch and returns the deltas and the final event.
+func collect(t *testing.T, ch <-chan llmproviders.StreamEvent) ([]string, llmproviders.StreamEvent) {
+	t.Helper()
+	var deltas []string
+	var final llmproviders.StreamEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return deltas, final
+			}
+			if ev.Done {
+				final = ev
+			} else {
+				deltas = append(deltas, ev.Delta)
+			}
+		case <-timeout:
+			t.Fatal("stream did not finish")
+		}
+	}
+}
+
+func TestStreamClassic(t *testing.T) {
+	c := sseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/chat/completions" || body["stream"] != true {
+			t.Errorf("unexpected request %s %v", r.URL.Path, body)
+		}
+		writeEvents(w,
+			": keep-alive",
+			`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+			`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+			`data: {"choices":[{"delta":{"content":"lo"}}]}`,
+			`data: {"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":2,"total_tokens":9}}`,
+			`data: [DONE]`,
+			`data: {"choices":[{"delta":{"content":"ignored"}}]}`,
+		)
+	})
+	ch, err := c.PromptWithContextStream(context.Background(), "hi", []string{"ctx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas, final := collect(t, ch)
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if !final.Done || final.Err != nil || final.Text != "Hello" {
+		t.Fatalf("final = %+v", final)
+	}
+	if final.Usage == nil || final.Usage.PromptTokens != 7 || final.Usage.CompletionTokens != 2 || final.Usage.TotalTokens != 9 {
+		t.Errorf("usage = %+v", final.Usage)
+	}
+}
+
+func TestStreamClassicHTTPError(t *testing.T) {
+	c := sseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"bad key"}}`, http.StatusUnauthorized)
+	})
+	if _, err := c.PromptWithContextStream(context.Background(), "hi", nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// assistantServer serves the thread and message calls and streams runEvents for the
+// run.
+func assistantServer(t *testing.T, runEvents ...string) *Client {
+	c := sseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/threads":
+			fmt.Fprint(w, `{"id":"thread_1"}`)
+		case "/threads/thread_1/messages":
+			fmt.Fprint(w, `{"id":"msg_1"}`)
+		case "/threads/thread_1/runs":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["assistant_id"] != "asst_1" || body["stream"] != true || body["additional_instructions"] != "ctx" {
+				t.Errorf("unexpected run request %v", body)
+			}
+			writeEvents(w, runEvents...)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	asst := "asst_1"
+	c.assistantID = &asst
+	return c
+}
+
+func TestStreamAssistant(t *testing.T) {
+	c := assistantServer(t,
+		"event: thread.run.created\ndata: {\"status\":\"queued\"}",
+		"event: thread.message.delta\ndata: {\"delta\":{\"content\":[{\"type\":\"text\",\"text\":{\"value\":\"Hi \"}}]}}",
+		"event: thread.message.delta\ndata: {\"delta\":{\"content\":[{\"type\":\"text\",\"text\":{\"value\":\"there\"}}]}}",
+		"event: thread.run.completed\ndata: {\"status\":\"completed\",\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":2,\"total_tokens\":5}}",
+		"event: done\ndata: [DONE]",
+		"event: thread.message.delta\ndata: {\"delta\":{\"content\":[{\"type\":\"text\",\"text\":{\"value\":\"ignored\"}}]}}",
+	)
+	ch, err := c.PromptWithContextStream(context.Background(), "hi", []string{"ctx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas, final := collect(t, ch)
+	if strings.Join(deltas, "|") != "Hi |there" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if !final.Done || final.Err != nil || final.Text != "Hi there" {
+		t.Fatalf("final = %+v", final)
+	}
+	if final.Usage == nil || final.Usage.TotalTokens != 5 {
+		t.Errorf("usage = %+v", final.Usage)
+	}
+}
+
+func TestStreamAssistantRunFailed(t *testing.T) {
+	c := assistantServer(t,
+		"event: thread.message.delta\ndata: {\"delta\":{\"content\":[{\"type\":\"text\",\"text\":{\"value\":\"partial\"}}]}}",
+		"event: thread.run.failed\ndata: {\"status\":\"failed\",\"last_error\":{\"code\":\"rate_limit_exceeded\",\"message\":\"quota exceeded\"}}",
+		"event: done\ndata: [DONE]",
+	)
+	ch, err := c.PromptWithContextStream(context.Background(), "hi", []string{"ctx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, final := collect(t, ch)
+	if !final.Done || final.Err == nil || !strings.Contains(final.Err.Error(), "quota exceeded") {
+		t.Fatalf("final = %+v", final)
+	}
+	if final.Text != "partial" {
+		t.Errorf("text = %q", final.Text)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	c := sseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, `data: {"choices":[{"delta":{"content":"first"}}]}`)
+		<-r.Context().Done() // never finishes on its own
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := c.PromptWithContextStream(ctx, "hi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-ch; ev.Delta != "first" {
+		t.Fatalf("first event = %+v", ev)
+	}
+	cancel()
+	_, final := collect(t, ch)
+	if final.Done && final.Err == nil {
+		t.Fatalf("cancelled stream finished without error: %+v", final)
+	}
+}
+
+func TestStreamTruncated(t *testing.T) {
+	c := sseServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// the connection drops before [DONE]
+		writeEvents(w, `data: {"choices":[{"delta":{"content":"Hel"}}]}`)
+	})
+	ch, err := c.PromptWithContextStream(context.Background(), "hi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas, final := collect(t, ch)
+	if strings.Join(deltas, "") != "Hel" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if !final.Done || !errors.Is(final.Err, io.ErrUnexpectedEOF) || final.Text != "Hel" {
+		t.Fatalf("final = %+v, want the partial text and io.ErrUnexpectedEOF", final)
+	}
+}
@@ -19,8 +19,37 @@ type LLM interface {
 	MaxContext() int
 }
 
+// Embedder turns text into vectors for storage and retrieval.
+type Embedder interface {
+	// Embed returns the embedding vector for a given text.
+	Embed(ctx context.Context, text string) ([]float64, error)
+
+	// EmbedBatch returns one embedding per text, in input order.
+	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
+
+	// Dimensions returns the length of the vectors produced, or 0 if not yet known.
+	Dimensions() int
+}
+
+// Generator answers prompts using retrieved context.
+type Generator interface {
+	// Name returns the name of the model/provider.
+	Name() string
+
+	// PromptWithContext sends a prompt and context to the model and returns the response.
+	PromptWithContext(ctx context.Context, prompt string, contextItems []string, opts ...PromptOption) (string, error)
+
+	// MaxContext returns the maximum number of context tokens supported by the model.
+	MaxContext() int
+}
+
 // PromptOption is a marker interface for provider-specific options.
 type PromptOption interface{}
 
 // List LLM's Supported
-const OPEN_AI = "openai"
+const (
+	OPEN_AI   = "openai"
+	ANTHROPIC = "anthropic"
+	OLLAMA    = "ollama"
+	GEMINI    = "gemini"
+)
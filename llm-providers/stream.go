@@ -0,0 +1,35 @@
+package llmproviders
+
+import (
+	"context"
+)
+
+// Usage reports the tokens consumed by a request, when the provider returns it.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// StreamEvent is one item of a streamed response. Deltas arrive with Delta set. The
+// final event has Done set and carries the aggregated Text and Usage, or Err if the
+// stream failed. The channel is closed after the final event.
+type StreamEvent struct {
+	Delta string
+	Done  bool
+	Text  string
+	Usage *Usage
+	Err   error
+}
+
+// Streamer is implemented by generators that can stream their answer.
+type Streamer interface {
+	// PromptWithContextStream is PromptWithContext, delivering the answer incrementally.
+	PromptWithContextStream(ctx context.Context, prompt string, contextItems []string, opts ...PromptOption) (<-chan StreamEvent, error)
+}
+
+// StreamingLLM is an LLM that can stream its answer as it is generated.
+type StreamingLLM interface {
+	LLM
+	Streamer
+}
@@ -0,0 +1,53 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// BatchError reports the items of a bulk write that failed, keyed by their index in
+// the input slice. Items not listed were stored.
+type BatchError struct {
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	idx := make([]int, 0, len(e.Failed))
+	for i := range e.Failed {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d items failed", len(idx))
+	for n, i := range idx {
+		if n == 3 {
+			sb.WriteString("; ...")
+			break
+		}
+		fmt.Fprintf(&sb, "; item %d: %v", i, e.Failed[i])
+	}
+	return sb.String()
+}
+
+// ValidateEmbedding checks an embedding before a bulk write. dims is the expected
+// vector length, or 0 to accept any non-empty vector.
+func ValidateEmbedding(emb Embedding, dims int) error {
+	if emb.ID == "" {
+		return errors.New("empty ID")
+	}
+	if len(emb.Vec) == 0 {
+		return errors.New("empty vector")
+	}
+	if dims > 0 && len(emb.Vec) != dims {
+		return fmt.Errorf("vector has %d dimensions, expected %d", len(emb.Vec), dims)
+	}
+	for _, v := range emb.Vec {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("vector contains NaN or Inf")
+		}
+	}
+	return nil
+}
@@ -10,10 +10,19 @@ import (
 
 // NewVectorDB returns a VectorDB implementation based on config.Type
 func NewVectorDB(cfg vector.Config) (vector.VectorDB, error) {
+	if err := cfg.Metric.Validate(); err != nil {
+		return nil, err
+	}
 	switch cfg.Type {
 	case vector.IN_MEMORY:
 		// import path: "github.com/shreetheja/ai-contextual-prompter/vector/local"
-		return local.NewInMemoryVectorDB(), nil
+		return local.NewInMemoryVectorDB(local.WithMetric(cfg.Metric), local.WithTextField(cfg.TextField)), nil
+	case vector.LOCAL_FILE:
+		db, err := local.NewFileVectorDB(cfg.Path, local.WithMetric(cfg.Metric), local.WithTextField(cfg.TextField), local.WithSnapshotEvery(cfg.SnapshotEvery))
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
 	case vector.PG_SQL:
 		// import path: "github.com/shreetheja/ai-contextual-prompter/vector/pgsql-vec"
 		entity, err := pgsqlvec.NewEntity(cfg)
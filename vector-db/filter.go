@@ -0,0 +1,302 @@
+package vector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// FilterOp is the operator of a Filter node.
+type FilterOp string
+
+const (
+	OpEq     FilterOp = "eq"
+	OpIn     FilterOp = "in"
+	OpGt     FilterOp = "gt"
+	OpGte    FilterOp = "gte"
+	OpLt     FilterOp = "lt"
+	OpLte    FilterOp = "lte"
+	OpExists FilterOp = "exists"
+	OpAnd    FilterOp = "and"
+	OpOr     FilterOp = "or"
+	OpNot    FilterOp = "not"
+)
+
+// Filter is a backend-neutral predicate over top-level Embedding.Meta keys.
+// Build it with Eq, In, Gt, Gte, Lt, Lte, Between, Exists, And, Or and Not.
+//
+// Numbers compare by value regardless of their Go type, strings compare bytewise and
+// time.Time values are compared as RFC 3339 UTC strings, so store dates that way.
+type Filter struct {
+	Op      FilterOp
+	Field   string
+	Value   interface{}   // eq and range operators
+	Values  []interface{} // in
+	Filters []*Filter     // and, or, not (exactly one)
+}
+
+// Eq matches field == value. Arrays and objects must match whole, element for
+// element (JSON equality), not by containment.
+func Eq(field string, value interface{}) *Filter {
+	return &Filter{Op: OpEq, Field: field, Value: value}
+}
+
+func In(field string, values ...interface{}) *Filter {
+	return &Filter{Op: OpIn, Field: field, Values: values}
+}
+
+func Gt(field string, value interface{}) *Filter {
+	return &Filter{Op: OpGt, Field: field, Value: value}
+}
+
+func Gte(field string, value interface{}) *Filter {
+	return &Filter{Op: OpGte, Field: field, Value: value}
+}
+
+func Lt(field string, value interface{}) *Filter {
+	return &Filter{Op: OpLt, Field: field, Value: value}
+}
+
+func Lte(field string, value interface{}) *Filter {
+	return &Filter{Op: OpLte, Field: field, Value: value}
+}
+
+// Between matches lo <= field <= hi.
+func Between(field string, lo, hi interface{}) *Filter {
+	return And(Gte(field, lo), Lte(field, hi))
+}
+
+func Exists(field string) *Filter {
+	return &Filter{Op: OpExists, Field: field}
+}
+
+func And(filters ...*Filter) *Filter {
+	return &Filter{Op: OpAnd, Filters: filters}
+}
+
+func Or(filters ...*Filter) *Filter {
+	return &Filter{Op: OpOr, Filters: filters}
+}
+
+func Not(f *Filter) *Filter {
+	return &Filter{Op: OpNot, Filters: []*Filter{f}}
+}
+
+// Validate reports malformed filters before they reach a backend.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	switch f.Op {
+	case OpEq, OpGt, OpGte, OpLt, OpLte:
+		if f.Field == "" {
+			return fmt.Errorf("filter %s: missing field", f.Op)
+		}
+		if f.Op != OpEq {
+			if _, ok := toFloat(f.Value); !ok {
+				if _, ok := toString(f.Value); !ok {
+					return fmt.Errorf("filter %s %s: value must be a number, string or time", f.Op, f.Field)
+				}
+			}
+		}
+	case OpIn, OpExists:
+		if f.Field == "" {
+			return fmt.Errorf("filter %s: missing field", f.Op)
+		}
+	case OpAnd, OpOr:
+		for _, c := range f.Filters {
+			if err := c.Validate(); err != nil {
+				return err
+			}
+		}
+	case OpNot:
+		if len(f.Filters) != 1 {
+			return fmt.Errorf("filter not: expects exactly one operand")
+		}
+		return f.Filters[0].Validate()
+	default:
+		return fmt.Errorf("unknown filter op: %s", f.Op)
+	}
+	return nil
+}
+
+// Match evaluates the filter against meta. A nil filter matches everything.
+func (f *Filter) Match(meta map[string]interface{}) bool {
+	if f == nil {
+		return true
+	}
+	switch f.Op {
+	case OpEq:
+		v, ok := meta[f.Field]
+		return ok && valuesEqual(v, f.Value)
+	case OpIn:
+		v, ok := meta[f.Field]
+		if !ok {
+			return false
+		}
+		for _, want := range f.Values {
+			if valuesEqual(v, want) {
+				return true
+			}
+		}
+		return false
+	case OpGt, OpGte, OpLt, OpLte:
+		v, ok := meta[f.Field]
+		if !ok {
+			return false
+		}
+		c, ok := compare(v, f.Value)
+		if !ok {
+			return false
+		}
+		switch f.Op {
+		case OpGt:
+			return c > 0
+		case OpGte:
+			return c >= 0
+		case OpLt:
+			return c < 0
+		default:
+			return c <= 0
+		}
+	case OpExists:
+		_, ok := meta[f.Field]
+		return ok
+	case OpAnd:
+		for _, c := range f.Filters {
+			if !c.Match(meta) {
+				return false
+			}
+		}
+		return true
+	case OpOr:
+		for _, c := range f.Filters {
+			if c.Match(meta) {
+				return true
+			}
+		}
+		return false
+	case OpNot:
+		return len(f.Filters) == 1 && !f.Filters[0].Match(meta)
+	}
+	return false
+}
+
+// NormalizeFilterValue converts a filter operand to the JSON-compatible value stored
+// in Meta: numbers become float64 and times become RFC 3339 UTC strings.
+func NormalizeFilterValue(v interface{}) interface{} {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+func valuesEqual(a, b interface{}) bool {
+	a, b = NormalizeFilterValue(a), NormalizeFilterValue(b)
+	if fa, ok := a.(float64); ok {
+		fb, ok := b.(float64)
+		return ok && fa == fb
+	}
+	if isComposite(a) || isComposite(b) {
+		// compare as JSON, so []string and the []interface{} a reload produces agree
+		ja, errA := json.Marshal(a)
+		jb, errB := json.Marshal(b)
+		return errA == nil && errB == nil && bytes.Equal(ja, jb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// isComposite reports whether v is an array, slice or map.
+func isComposite(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// compare orders two numbers or two strings. ok is false for mismatched types.
+func compare(a, b interface{}) (int, bool) {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case fa < fb:
+			return -1, true
+		case fa > fb:
+			return 1, true
+		}
+		return 0, true
+	}
+	sa, ok := toString(a)
+	if !ok {
+		return 0, false
+	}
+	sb, ok := toString(b)
+	if !ok {
+		return 0, false
+	}
+	switch {
+	case sa < sb:
+		return -1, true
+	case sa > sb:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case time.Time:
+		return s.UTC().Format(time.RFC3339), true
+	}
+	return "", false
+}
+
+// IsNumeric reports whether v is compared as a number by range filters.
+func IsNumeric(v interface{}) bool {
+	_, ok := toFloat(v)
+	return ok
+}
@@ -0,0 +1,39 @@
+package vector
+
+import "testing"
+
+func TestFilterMatch(t *testing.T) {
+	meta := map[string]interface{}{
+		"color": "red",
+		"size":  3,
+		"tags":  []interface{}{"a", "b"}, // as decoded from JSON
+		"dims":  map[string]interface{}{"w": 1.0},
+	}
+	tests := []struct {
+		name   string
+		filter *Filter
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"eq", Eq("color", "red"), true},
+		{"eq number across types", Eq("size", 3.0), true},
+		{"eq array", Eq("tags", []string{"a", "b"}), true},
+		{"eq array is not containment", Eq("tags", []string{"a"}), false},
+		{"eq array order", Eq("tags", []string{"b", "a"}), false},
+		{"eq object", Eq("dims", map[string]int{"w": 1}), true},
+		{"in array", In("tags", []string{"x"}, []string{"a", "b"}), true},
+		{"range", Between("size", 1, 3), true},
+		{"missing field", Gt("weight", 1), false},
+		{"and nil", And(Eq("color", "red"), nil), true},
+		{"or nil", Or(Eq("color", "blue"), nil), true},
+		{"not nil", Not(nil), false},
+	}
+	for _, tt := range tests {
+		if err := tt.filter.Validate(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if got := tt.filter.Match(meta); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package vector
+
+import (
+	"context"
+	"sort"
+)
+
+// TextSearcher is implemented by stores that can also rank by keywords, which finds
+// exact identifiers (error codes, SKUs, function names) that embeddings blur. The
+// query is free text; Filter is honoured, MinScore is not because keyword scores
+// are not comparable across queries.
+type TextSearcher interface {
+	TextSearch(ctx context.Context, query string, topK int, opts ...SearchOption) ([]SearchResult, error)
+}
+
+// DefaultTextField is the Meta key keyword search indexes when Config.TextField is
+// empty. It matches where context_prompter stores the text.
+const DefaultTextField = "text"
+
+// Fusion merges a vector ranking and a keyword ranking into one list, best first.
+// Fused results carry the combined Score, Distance -Score and Metric Fused.
+type Fusion func(vec, text []SearchResult) []SearchResult
+
+const defaultRRFK = 60
+
+// RRF is reciprocal rank fusion: each list contributes 1/(k+rank) for every item it
+// ranks. It needs no score calibration; k (60 when <= 0) damps the weight of the
+// top ranks.
+func RRF(k int) Fusion {
+	if k <= 0 {
+		k = defaultRRFK
+	}
+	return func(vec, text []SearchResult) []SearchResult {
+		f := newFuser()
+		for _, list := range [][]SearchResult{vec, text} {
+			for rank, r := range list {
+				f.add(r, 1/float64(k+rank+1))
+			}
+		}
+		return f.results()
+	}
+}
+
+// Weighted scales each list's scores to [0, 1] and sums them, weighting the vector
+// score by alpha and the keyword score by 1-alpha.
+func Weighted(alpha float64) Fusion {
+	return func(vec, text []SearchResult) []SearchResult {
+		f := newFuser()
+		for _, l := range []struct {
+			list []SearchResult
+			w    float64
+		}{{vec, alpha}, {text, 1 - alpha}} {
+			lo, hi := scoreRange(l.list)
+			for _, r := range l.list {
+				norm := 1.0
+				if hi > lo {
+					norm = (r.Score - lo) / (hi - lo)
+				}
+				f.add(r, l.w*norm)
+			}
+		}
+		return f.results()
+	}
+}
+
+type fuser struct {
+	order []string
+	byID  map[string]*SearchResult
+}
+
+func newFuser() *fuser {
+	return &fuser{byID: make(map[string]*SearchResult)}
+}
+
+func (f *fuser) add(r SearchResult, score float64) {
+	if cur, ok := f.byID[r.ID]; ok {
+		cur.Score += score
+		if cur.Vec == nil {
+			cur.Vec = r.Vec
+		}
+		return
+	}
+	r.Score = score
+	f.byID[r.ID] = &r
+	f.order = append(f.order, r.ID)
+}
+
+func (f *fuser) results() []SearchResult {
+	out := make([]SearchResult, len(f.order))
+	for i, id := range f.order {
+		r := *f.byID[id]
+		r.Distance, r.Metric = -r.Score, Fused
+		out[i] = r
+	}
+	// stable, so ties keep vector-first order
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+func scoreRange(list []SearchResult) (lo, hi float64) {
+	for i, r := range list {
+		if i == 0 || r.Score < lo {
+			lo = r.Score
+		}
+		if i == 0 || r.Score > hi {
+			hi = r.Score
+		}
+	}
+	return lo, hi
+}
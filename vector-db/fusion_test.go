@@ -0,0 +1,66 @@
+package vector
+
+import (
+	"math"
+	"testing"
+)
+
+func results(scores map[string]float64, ids ...string) []SearchResult {
+	out := make([]SearchResult, len(ids))
+	for i, id := range ids {
+		out[i] = SearchResult{Embedding: Embedding{ID: id}, Score: scores[id]}
+	}
+	return out
+}
+
+func TestFusion(t *testing.T) {
+	vec := results(map[string]float64{"a": 0.9, "b": 0.5, "c": 0.1}, "a", "b", "c")
+	vec[2].Vec = []float64{1, 2}
+	text := results(map[string]float64{"c": 10, "d": 4}, "c", "d")
+	tests := []struct {
+		name   string
+		fusion Fusion
+		order  string
+		scores []float64
+	}{
+		// c is ranked by both lists; b and d tie at rank 2 and keep vector-first order
+		{"rrf default k", RRF(0), "cabd", []float64{1.0/63 + 1.0/61, 1.0 / 61, 1.0 / 62, 1.0 / 62}},
+		{"rrf k=1", RRF(1), "cabd", []float64{1.0/4 + 1.0/2, 1.0 / 2, 1.0 / 3, 1.0 / 3}},
+		// scaled vector scores are a=1, b=0.5, c=0; keyword scores c=1, d=0
+		{"weighted even", Weighted(0.5), "acbd", []float64{0.5, 0.5, 0.25, 0}},
+		{"weighted vector only", Weighted(1), "abcd", []float64{1, 0.5, 0, 0}},
+		{"weighted keyword only", Weighted(0), "cabd", []float64{1, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fusion(vec, text)
+			order := ""
+			for i, r := range got {
+				order += r.ID
+				if i < len(tt.scores) && math.Abs(r.Score-tt.scores[i]) > 1e-12 {
+					t.Errorf("%s: score %v, want %v", r.ID, r.Score, tt.scores[i])
+				}
+				if r.Metric != Fused || r.Distance != -r.Score {
+					t.Errorf("%s: metric %s distance %v", r.ID, r.Metric, r.Distance)
+				}
+				if r.ID == "c" && len(r.Vec) != 2 {
+					t.Errorf("c lost its vector")
+				}
+			}
+			if order != tt.order {
+				t.Errorf("order %q, want %q", order, tt.order)
+			}
+		})
+	}
+}
+
+func TestWeightedSingleScore(t *testing.T) {
+	// a list whose scores are all equal scales to 1, not 0
+	got := Weighted(0.25)(nil, results(map[string]float64{"x": 3, "y": 3}, "x", "y"))
+	if len(got) != 2 || got[0].ID != "x" || got[0].Score != 0.75 || got[1].Score != 0.75 {
+		t.Errorf("got %+v", got)
+	}
+	if got := RRF(0)(nil, nil); len(got) != 0 {
+		t.Errorf("empty lists fused to %v", got)
+	}
+}
@@ -0,0 +1,342 @@
+package local
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// HNSWConfig tunes the approximate nearest-neighbour index. Zero values fall back to
+// the defaults below.
+type HNSWConfig struct {
+	M              int   // links per node on upper layers (2*M on layer 0), default 16
+	EfConstruction int   // candidate list size while inserting, default 200
+	EfSearch       int   // candidate list size while searching, default 64
+	Seed           int64 // level generator seed, for reproducible graphs
+}
+
+func (c HNSWConfig) withDefaults() HNSWConfig {
+	if c.M <= 1 {
+		c.M = 16
+	}
+	if c.EfConstruction <= 0 {
+		c.EfConstruction = 200
+	}
+	if c.EfSearch <= 0 {
+		c.EfSearch = 64
+	}
+	return c
+}
+
+type hnswNode struct {
+	id    string
+	vec   []float64
+	links [][]string // neighbour IDs per layer, 0..level
+}
+
+// hnswIndex is a Hierarchical Navigable Small World graph (Malkov & Yashunin) over
+// the store's vectors. It is guarded by InMemoryVectorDB.mu: search only reads the
+// graph, so concurrent searches are safe under the read lock.
+type hnswIndex struct {
+	cfg      HNSWConfig
+	ml       float64
+	prep     func([]float64) []float64 // applied to stored and query vectors
+	dist     func(a, b []float64) float64
+	rng      *rand.Rand
+	nodes    map[string]*hnswNode
+	entry    string
+	maxLevel int
+}
+
+func newHNSW(cfg HNSWConfig, prep func([]float64) []float64, dist func(a, b []float64) float64) *hnswIndex {
+	cfg = cfg.withDefaults()
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	return &hnswIndex{
+		cfg:   cfg,
+		ml:    1 / math.Log(float64(cfg.M)),
+		prep:  prep,
+		dist:  dist,
+		rng:   rand.New(rand.NewSource(seed)),
+		nodes: make(map[string]*hnswNode),
+	}
+}
+
+// normalize returns vec scaled to unit length, so cosine distance becomes 1 - dot.
+func normalize(vec []float64) []float64 {
+	var n float64
+	for _, v := range vec {
+		n += v * v
+	}
+	out := make([]float64, len(vec))
+	if n == 0 {
+		return out
+	}
+	n = math.Sqrt(n)
+	for i, v := range vec {
+		out[i] = v / n
+	}
+	return out
+}
+
+func identity(vec []float64) []float64 { return vec }
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+type candidate struct {
+	id   string
+	dist float64
+}
+
+// candHeap is a min-heap by distance, or a max-heap when max is set.
+type candHeap struct {
+	items []candidate
+	max   bool
+}
+
+func (h *candHeap) Len() int { return len(h.items) }
+func (h *candHeap) Less(i, j int) bool {
+	if h.max {
+		return h.items[i].dist > h.items[j].dist
+	}
+	return h.items[i].dist < h.items[j].dist
+}
+func (h *candHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *candHeap) Push(x interface{}) { h.items = append(h.items, x.(candidate)) }
+func (h *candHeap) Pop() interface{} {
+	old := h.items
+	c := old[len(old)-1]
+	h.items = old[:len(old)-1]
+	return c
+}
+func (h *candHeap) top() candidate { return h.items[0] }
+
+func (ix *hnswIndex) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * ix.cfg.M
+	}
+	return ix.cfg.M
+}
+
+func (ix *hnswIndex) randomLevel() int {
+	return int(math.Floor(-math.Log(1-ix.rng.Float64()) * ix.ml))
+}
+
+func (ix *hnswIndex) len() int {
+	return len(ix.nodes)
+}
+
+// insert adds or replaces the vector stored under id.
+func (ix *hnswIndex) insert(id string, vec []float64) {
+	if _, ok := ix.nodes[id]; ok {
+		ix.remove(id)
+	}
+	vec = ix.prep(vec)
+	level := ix.randomLevel()
+	node := &hnswNode{id: id, vec: vec, links: make([][]string, level+1)}
+	if len(ix.nodes) == 0 {
+		ix.nodes[id] = node
+		ix.entry, ix.maxLevel = id, level
+		return
+	}
+	ix.nodes[id] = node
+
+	ep := []candidate{{ix.entry, ix.dist(vec, ix.nodes[ix.entry].vec)}}
+	for l := ix.maxLevel; l > level; l-- {
+		ep = ix.searchLayer(vec, ep, 1, l)
+	}
+	top := level
+	if ix.maxLevel < top {
+		top = ix.maxLevel
+	}
+	for l := top; l >= 0; l-- {
+		w := ix.searchLayer(vec, ep, ix.cfg.EfConstruction, l)
+		neighbours := ix.selectNeighbours(w, ix.cfg.M, id)
+		node.links[l] = neighbours
+		for _, nid := range neighbours {
+			n := ix.nodes[nid]
+			n.links[l] = append(n.links[l], id)
+			if len(n.links[l]) > ix.maxLinks(l) {
+				n.links[l] = ix.shrink(n, l)
+			}
+		}
+		ep = w
+	}
+	if level > ix.maxLevel {
+		ix.entry, ix.maxLevel = id, level
+	}
+}
+
+// remove deletes id and reconnects its former neighbours among themselves.
+func (ix *hnswIndex) remove(id string) {
+	node, ok := ix.nodes[id]
+	if !ok {
+		return
+	}
+	delete(ix.nodes, id)
+	for l, links := range node.links {
+		for _, nid := range links {
+			n, ok := ix.nodes[nid]
+			if !ok || len(n.links) <= l {
+				continue
+			}
+			// candidates: n's remaining links plus the removed node's links
+			seen := map[string]bool{nid: true}
+			var cands []candidate
+			for _, lst := range [][]string{n.links[l], links} {
+				for _, cid := range lst {
+					c, ok := ix.nodes[cid]
+					if !ok || seen[cid] {
+						continue
+					}
+					seen[cid] = true
+					cands = append(cands, candidate{cid, ix.dist(n.vec, c.vec)})
+				}
+			}
+			sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+			n.links[l] = ix.selectNeighbours(cands, ix.maxLinks(l), nid)
+		}
+	}
+	if ix.entry == id {
+		ix.entry, ix.maxLevel = "", 0
+		for nid, n := range ix.nodes {
+			if ix.entry == "" || len(n.links)-1 > ix.maxLevel {
+				ix.entry, ix.maxLevel = nid, len(n.links)-1
+			}
+		}
+	}
+}
+
+// search returns up to k nearest candidates, closest first, exploring ef candidates
+// on the bottom layer.
+func (ix *hnswIndex) search(q []float64, k, ef int) []candidate {
+	if len(ix.nodes) == 0 || k <= 0 {
+		return nil
+	}
+	if ef < k {
+		ef = k
+	}
+	q = ix.prep(q)
+	ep := []candidate{{ix.entry, ix.dist(q, ix.nodes[ix.entry].vec)}}
+	for l := ix.maxLevel; l > 0; l-- {
+		ep = ix.searchLayer(q, ep, 1, l)
+	}
+	w := ix.searchLayer(q, ep, ef, 0)
+	if len(w) > k {
+		w = w[:k]
+	}
+	return w
+}
+
+// searchLayer is the greedy beam search of the paper, returning up to ef candidates
+// sorted closest first.
+func (ix *hnswIndex) searchLayer(q []float64, ep []candidate, ef, level int) []candidate {
+	visited := make(map[string]bool, ef*4)
+	cands := &candHeap{}
+	results := &candHeap{max: true}
+	for _, c := range ep {
+		if _, ok := ix.nodes[c.id]; !ok || visited[c.id] {
+			continue
+		}
+		visited[c.id] = true
+		heap.Push(cands, c)
+		heap.Push(results, c)
+	}
+	for cands.Len() > 0 {
+		c := heap.Pop(cands).(candidate)
+		if results.Len() >= ef && c.dist > results.top().dist {
+			break
+		}
+		node := ix.nodes[c.id]
+		if node == nil || len(node.links) <= level {
+			continue
+		}
+		for _, nid := range node.links[level] {
+			if visited[nid] {
+				continue
+			}
+			visited[nid] = true
+			n, ok := ix.nodes[nid]
+			if !ok {
+				continue // dangling link to a removed node
+			}
+			d := ix.dist(q, n.vec)
+			if results.Len() < ef || d < results.top().dist {
+				heap.Push(cands, candidate{nid, d})
+				heap.Push(results, candidate{nid, d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+	out := make([]candidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(candidate)
+	}
+	return out
+}
+
+// selectNeighbours applies the paper's diversity heuristic to candidates sorted
+// closest first: a candidate is kept only if it is closer to the base node than to
+// any neighbour already kept. Remaining slots are filled with the closest leftovers.
+func (ix *hnswIndex) selectNeighbours(cands []candidate, m int, self string) []string {
+	var kept []candidate
+	var skipped []candidate
+	for _, c := range cands {
+		if c.id == self {
+			continue
+		}
+		if len(kept) >= m {
+			break
+		}
+		good := true
+		cv := ix.nodes[c.id].vec
+		for _, k := range kept {
+			if ix.dist(cv, ix.nodes[k.id].vec) < c.dist {
+				good = false
+				break
+			}
+		}
+		if good {
+			kept = append(kept, c)
+		} else {
+			skipped = append(skipped, c)
+		}
+	}
+	for _, c := range skipped {
+		if len(kept) >= m {
+			break
+		}
+		kept = append(kept, c)
+	}
+	out := make([]string, len(kept))
+	for i, c := range kept {
+		out[i] = c.id
+	}
+	return out
+}
+
+// shrink trims n's links on level l back to the layer's limit.
+func (ix *hnswIndex) shrink(n *hnswNode, l int) []string {
+	cands := make([]candidate, 0, len(n.links[l]))
+	for _, nid := range n.links[l] {
+		if c, ok := ix.nodes[nid]; ok {
+			cands = append(cands, candidate{nid, ix.dist(n.vec, c.vec)})
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool { return cands[i].dist < cands[j].dist })
+	return ix.selectNeighbours(cands, ix.maxLinks(l), n.id)
+}
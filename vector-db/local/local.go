@@ -2,67 +2,351 @@ package local
 
 import (
 	"context"
+	"errors"
 	"sort"
+	"sync"
 
 	"github.com/shreetheja/ai-contextual-prompter/vector-db"
 )
 
-// InMemoryVectorDB is an in-memory implementation of VectorDB.
+// InMemoryVectorDB is an in-memory implementation of VectorDB. Search scans every
+// vector unless an HNSW index is enabled with WithHNSW. NewFileVectorDB adds an
+// on-disk log so the store survives restarts.
+//
+// It is safe for concurrent use: writers take an exclusive lock, so every Search
+// sees a consistent snapshot of the store, and searches run in parallel.
+//
+// Each namespace is a separate partition with its own map and index; views returned
+// by WithNamespace share the lock and the log with the store they came from.
 type InMemoryVectorDB struct {
+	*engine
+	ns string
+}
+
+type engine struct {
+	mu        sync.RWMutex
+	parts     map[string]*partition
+	hnsw      *HNSWConfig
+	metric    vector.Metric
+	textField string
+
+	wal       *walLog // nil when purely in memory or closed
+	closed    bool
+	snapEvery int
+}
+
+type partition struct {
 	store map[string]vector.Embedding
+	index *hnswIndex
+	text  *vector.TextIndex
+}
+
+// Option configures an InMemoryVectorDB.
+type Option func(*InMemoryVectorDB)
+
+// WithHNSW enables the approximate HNSW index for Search.
+func WithHNSW(cfg HNSWConfig) Option {
+	return func(db *InMemoryVectorDB) {
+		cfg = cfg.withDefaults()
+		db.hnsw = &cfg
+	}
+}
+
+// WithMetric sets the distance used to rank results (default vector.Cosine).
+func WithMetric(m vector.Metric) Option {
+	return func(db *InMemoryVectorDB) { db.metric = m }
+}
+
+func NewInMemoryVectorDB(opts ...Option) *InMemoryVectorDB {
+	db := &InMemoryVectorDB{engine: &engine{parts: make(map[string]*partition)}}
+	for _, o := range opts {
+		o(db)
+	}
+	db.metric = db.metric.OrDefault()
+	if db.textField == "" {
+		db.textField = vector.DefaultTextField
+	}
+	return db
+}
+
+// WithNamespace returns a view of the store scoped to namespace ns.
+func (db *InMemoryVectorDB) WithNamespace(ns string) vector.VectorDB {
+	return &InMemoryVectorDB{engine: db.engine, ns: ns}
+}
+
+func (e *engine) newPartition() *partition {
+	p := &partition{store: make(map[string]vector.Embedding), text: vector.NewTextIndex(vector.DefaultBM25K1, vector.DefaultBM25B)}
+	if e.hnsw == nil {
+		return p
+	}
+	switch e.metric {
+	case vector.Cosine:
+		// vectors are normalised on the way in, so cosine distance is 1 - dot
+		p.index = newHNSW(*e.hnsw, normalize, func(a, b []float64) float64 { return 1 - dot(a, b) })
+	default:
+		p.index = newHNSW(*e.hnsw, identity, e.metric.Distance)
+	}
+	return p
+}
+
+// part returns the partition for ns, or an empty one that is not retained.
+func (e *engine) part(ns string) *partition {
+	if p, ok := e.parts[ns]; ok {
+		return p
+	}
+	return &partition{}
 }
 
-func NewInMemoryVectorDB() *InMemoryVectorDB {
-	return &InMemoryVectorDB{store: make(map[string]vector.Embedding)}
+// put stores emb in namespace ns and keeps the index in sync.
+func (e *engine) put(ns string, emb vector.Embedding) {
+	p, ok := e.parts[ns]
+	if !ok {
+		p = e.newPartition()
+		e.parts[ns] = p
+	}
+	p.store[emb.ID] = emb
+	if p.index != nil {
+		p.index.insert(emb.ID, emb.Vec)
+	}
+	text, _ := emb.Meta[e.textField].(string)
+	p.text.Add(emb.ID, text)
+}
+
+func (e *engine) remove(ns, id string) {
+	p, ok := e.parts[ns]
+	if !ok {
+		return
+	}
+	delete(p.store, id)
+	if p.index != nil {
+		p.index.remove(id)
+	}
+	p.text.Remove(id)
+	if len(p.store) == 0 {
+		delete(e.parts, ns)
+	}
 }
 
-// AddN adds multiple vector.embeddings to the database.
+func (e *engine) reset(ns string) {
+	delete(e.parts, ns)
+}
+
+// AddN adds multiple vector.embeddings to the database. Invalid items are skipped
+// and reported in a *vector.BatchError.
 func (db *InMemoryVectorDB) AddN(ctx context.Context, embs []vector.Embedding) error {
-	for _, emb := range embs {
-		db.store[emb.ID] = emb
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	failed := map[int]error{}
+	valid := make([]vector.Embedding, 0, len(embs))
+	for i, emb := range embs {
+		if err := vector.ValidateEmbedding(emb, 0); err != nil {
+			failed[i] = err
+			continue
+		}
+		valid = append(valid, emb)
+	}
+	if len(valid) > 0 {
+		if err := db.logRecord(walRecord{Op: opAdd, NS: db.ns, Embs: valid}); err != nil {
+			return err
+		}
+		for _, emb := range valid {
+			db.put(db.ns, emb)
+		}
+	}
+	// compact even when some items failed: the valid ones were logged
+	err := db.maybeCompact()
+	if len(failed) == 0 {
+		return err
+	}
+	be := &vector.BatchError{Failed: failed}
+	if err != nil {
+		return errors.Join(be, err)
 	}
-	return nil
+	return be
 }
 
 func (db *InMemoryVectorDB) Type(ctx context.Context) string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	if db.wal != nil || db.closed {
+		return vector.LOCAL_FILE
+	}
 	return vector.IN_MEMORY
 }
 
 func (db *InMemoryVectorDB) Add(ctx context.Context, emb vector.Embedding) error {
-	db.store[emb.ID] = emb
-	return nil
+	if err := vector.ValidateEmbedding(emb, 0); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.logRecord(walRecord{Op: opAdd, NS: db.ns, Embs: []vector.Embedding{emb}}); err != nil {
+		return err
+	}
+	db.put(db.ns, emb)
+	return db.maybeCompact()
 }
 
-func (db *InMemoryVectorDB) Search(ctx context.Context, query []float64, topK int) ([]vector.Embedding, error) {
-	type scored struct {
-		emb   vector.Embedding
-		score float64
+func (db *InMemoryVectorDB) Search(ctx context.Context, query []float64, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	o := vector.NewSearchOptions(opts...)
+	if err := o.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if topK <= 0 {
+		return nil, nil
 	}
-	var scoredList []scored
-	for _, emb := range db.store {
-		sim := vector.CosineSimilarity(query, emb.Vec)
-		scoredList = append(scoredList, scored{emb: emb, score: sim})
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	p := db.part(db.ns)
+	if p.index != nil {
+		if res, ok := db.searchIndex(p, query, topK, o); ok {
+			return res, nil
+		}
+	}
+	return db.searchExact(p, query, topK, o), nil
+}
+
+// searchExact scores every stored vector.
+func (db *InMemoryVectorDB) searchExact(p *partition, query []float64, topK int, o vector.SearchOptions) []vector.SearchResult {
+	var scoredList []vector.SearchResult
+	for _, emb := range p.store {
+		if !o.Filter.Match(emb.Meta) {
+			continue
+		}
+		res := db.score(query, emb)
+		if o.MinScore != nil && res.Score < *o.MinScore {
+			continue
+		}
+		scoredList = append(scoredList, res)
 	}
 	sort.Slice(scoredList, func(i, j int) bool {
-		return scoredList[i].score > scoredList[j].score
+		return scoredList[i].Score > scoredList[j].Score
 	})
-	var result []vector.Embedding
-	for i := 0; i < topK && i < len(scoredList); i++ {
-		result = append(result, scoredList[i].emb)
+	if len(scoredList) > topK {
+		scoredList = scoredList[:topK]
 	}
-	return result, nil
+	return scoredList
+}
+
+func (db *InMemoryVectorDB) score(query []float64, emb vector.Embedding) vector.SearchResult {
+	d := db.metric.Distance(query, emb.Vec)
+	return vector.SearchResult{Embedding: emb, Score: db.metric.Score(d), Distance: d, Metric: db.metric}
+}
+
+// searchIndex answers from the HNSW graph. With a filter it over-fetches and
+// reports ok=false if too few candidates pass, so the caller can fall back to an
+// exact scan.
+func (db *InMemoryVectorDB) searchIndex(p *partition, query []float64, topK int, o vector.SearchOptions) ([]vector.SearchResult, bool) {
+	k := topK
+	ef := db.hnsw.EfSearch
+	if o.Filter != nil {
+		k = topK * 4
+		if ef < k {
+			ef = k
+		}
+	}
+	var out []vector.SearchResult
+	for _, c := range p.index.search(query, k, ef) {
+		emb := p.store[c.id]
+		if !o.Filter.Match(emb.Meta) {
+			continue
+		}
+		// rescore exactly so scores match the brute-force path
+		res := db.score(query, emb)
+		if o.MinScore != nil && res.Score < *o.MinScore {
+			break // candidates are sorted, the rest score lower
+		}
+		out = append(out, res)
+		if len(out) == topK {
+			break
+		}
+	}
+	if o.Filter != nil && len(out) < topK && p.index.len() > k {
+		return nil, false
+	}
+	return out, true
+}
+
+// Recall measures how many of the exact top-k neighbours the HNSW index returns for
+// the given queries, averaged over queries (1.0 means identical). It returns 1 when
+// no index is enabled. Use it to tune EfSearch against your data.
+func (db *InMemoryVectorDB) Recall(queries [][]float64, k int) float64 {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	p := db.part(db.ns)
+	if p.index == nil || len(queries) == 0 {
+		return 1
+	}
+	var total float64
+	for _, q := range queries {
+		exact := db.searchExact(p, q, k, vector.SearchOptions{})
+		if len(exact) == 0 {
+			total++
+			continue
+		}
+		want := make(map[string]bool, len(exact))
+		for _, r := range exact {
+			want[r.ID] = true
+		}
+		hits := 0
+		for _, c := range p.index.search(q, k, db.hnsw.EfSearch) {
+			if want[c.id] {
+				hits++
+			}
+		}
+		total += float64(hits) / float64(len(exact))
+	}
+	return total / float64(len(queries))
 }
 
 func (db *InMemoryVectorDB) Count(ctx context.Context) (int, error) {
-	return len(db.store), nil
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return len(db.part(db.ns).store), nil
 }
 
 func (db *InMemoryVectorDB) Delete(ctx context.Context, id string) error {
-	delete(db.store, id)
-	return nil
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.logRecord(walRecord{Op: opDelete, NS: db.ns, ID: id}); err != nil {
+		return err
+	}
+	db.remove(db.ns, id)
+	return db.maybeCompact()
+}
+
+// DeleteWhere removes every embedding in the namespace whose Meta matches f.
+func (db *InMemoryVectorDB) DeleteWhere(ctx context.Context, f *vector.Filter) (int, error) {
+	if err := f.Validate(); err != nil {
+		return 0, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var ids []string
+	for id, emb := range db.part(db.ns).store {
+		if f.Match(emb.Meta) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	sort.Strings(ids)
+	if err := db.logRecord(walRecord{Op: opDelete, NS: db.ns, IDs: ids}); err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		db.remove(db.ns, id)
+	}
+	return len(ids), db.maybeCompact()
 }
 
 func (db *InMemoryVectorDB) Clear(ctx context.Context) error {
-	db.store = make(map[string]vector.Embedding)
-	return nil
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.logRecord(walRecord{Op: opClear, NS: db.ns}); err != nil {
+		return err
+	}
+	db.reset(db.ns)
+	return db.maybeCompact()
 }
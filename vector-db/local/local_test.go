@@ -0,0 +1,289 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// minRecall is the recall@10 the HNSW index must reach against the exact scan with
+// the default parameters.
+const minRecall = 0.9
+
+func randomVecs(rng *rand.Rand, n, dims int) [][]float64 {
+	vecs := make([][]float64, n)
+	for i := range vecs {
+		v := make([]float64, dims)
+		for j := range v {
+			v[j] = rng.NormFloat64()
+		}
+		vecs[i] = v
+	}
+	return vecs
+}
+
+// fill adds n random embeddings with IDs "0".."n-1".
+func fill(t testing.TB, db *InMemoryVectorDB, rng *rand.Rand, n, dims int) {
+	t.Helper()
+	embs := make([]vector.Embedding, n)
+	for i, v := range randomVecs(rng, n, dims) {
+		embs[i] = vector.Embedding{ID: fmt.Sprint(i), Vec: v, Meta: map[string]interface{}{"text": fmt.Sprintf("doc %d group%d", i, i%10)}}
+	}
+	if err := db.AddN(context.Background(), embs); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHNSWRecall(t *testing.T) {
+	ctx := context.Background()
+	for _, m := range []vector.Metric{vector.Cosine, vector.L2, vector.InnerProduct} {
+		t.Run(string(m), func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			db := NewInMemoryVectorDB(WithHNSW(HNSWConfig{Seed: 1}), WithMetric(m))
+			fill(t, db, rng, 1000, 16)
+			queries := randomVecs(rng, 50, 16)
+
+			r := db.Recall(queries, 10)
+			t.Logf("recall@10 = %.3f", r)
+			if r < minRecall {
+				t.Errorf("recall = %.3f, want >= %.2f", r, minRecall)
+			}
+
+			// deleting a third of the graph must not disconnect it
+			deleted := map[string]bool{}
+			for i := 0; i < 1000; i += 3 {
+				id := fmt.Sprint(i)
+				if err := db.Delete(ctx, id); err != nil {
+					t.Fatal(err)
+				}
+				deleted[id] = true
+			}
+			r = db.Recall(queries, 10)
+			t.Logf("recall@10 after deletes = %.3f", r)
+			if r < minRecall {
+				t.Errorf("recall after deletes = %.3f, want >= %.2f", r, minRecall)
+			}
+			for _, q := range queries {
+				res, err := db.Search(ctx, q, 10)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(res) != 10 {
+					t.Fatalf("got %d results, want 10", len(res))
+				}
+				for _, r := range res {
+					if deleted[r.ID] {
+						t.Fatalf("deleted %s returned", r.ID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHNSWScoresMatchExact(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	exact := NewInMemoryVectorDB(WithMetric(vector.L2))
+	indexed := NewInMemoryVectorDB(WithHNSW(HNSWConfig{Seed: 2}), WithMetric(vector.L2))
+	fill(t, exact, rand.New(rand.NewSource(3)), 500, 16)
+	fill(t, indexed, rand.New(rand.NewSource(3)), 500, 16)
+	q := randomVecs(rng, 1, 16)[0]
+	want, _ := exact.Search(context.Background(), q, 5)
+	got, _ := indexed.Search(context.Background(), q, 5)
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].ID != want[i].ID || got[i].Score != want[i].Score || got[i].Distance != want[i].Distance {
+			t.Errorf("result %d: indexed %s %v, exact %s %v", i, got[i].ID, got[i].Score, want[i].ID, want[i].Score)
+		}
+	}
+}
+
+// TestSearchOrderMatchesPgvector ranks the fixture of vector-db's metric tests and
+// expects the order of pgvector's ORDER BY q <op> vec.
+func TestSearchOrderMatchesPgvector(t *testing.T) {
+	q := []float64{1, 2}
+	docs := map[string][]float64{"a": {1, 2}, "b": {0, 3}, "c": {2, -1}, "d": {-1, -2}, "e": {3, 0}, "f": {4, 7}}
+	want := map[vector.Metric]string{
+		vector.Cosine:       "afbecd", // <=>
+		vector.L2:           "abecdf", // <->
+		vector.InnerProduct: "fbaecd", // <#>
+	}
+	for m, order := range want {
+		for _, opts := range [][]Option{nil, {WithHNSW(HNSWConfig{Seed: 1})}} {
+			db := NewInMemoryVectorDB(append(opts, WithMetric(m))...)
+			for id, v := range docs {
+				db.Add(context.Background(), vector.Embedding{ID: id, Vec: v})
+			}
+			res, err := db.Search(context.Background(), q, len(docs))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			for _, r := range res {
+				got += r.ID
+				if r.Score != m.Score(r.Distance) || r.Metric != m {
+					t.Errorf("%s: %s has score %v for distance %v", m, r.ID, r.Score, r.Distance)
+				}
+			}
+			if got != order {
+				t.Errorf("%s (hnsw=%v): order %s, pgvector %s", m, opts != nil, got, order)
+			}
+		}
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"exact", nil},
+		{"hnsw", []Option{WithHNSW(HNSWConfig{Seed: 1})}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			rng := rand.New(rand.NewSource(1))
+			db := NewInMemoryVectorDB(bc.opts...)
+			fill(b, db, rng, 5000, 32)
+			queries := randomVecs(rng, 100, 32)
+			b.ReportMetric(db.Recall(queries, 10), "recall@10")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.Search(context.Background(), queries[i%len(queries)], 10)
+			}
+		})
+	}
+}
+
+// TestConcurrentAccess mixes every operation from many goroutines; run it with
+// go test -race.
+func TestConcurrentAccess(t *testing.T) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"exact", nil},
+		{"hnsw", []Option{WithHNSW(HNSWConfig{M: 8, EfConstruction: 32, Seed: 1})}},
+	} {
+		t.Run(bc.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := NewInMemoryVectorDB(bc.opts...)
+			fill(t, db, rand.New(rand.NewSource(1)), 200, 8)
+			tenant := db.WithNamespace("tenant")
+
+			const workers, ops = 8, 96
+			var wg sync.WaitGroup
+			errs := make(chan error, workers*ops)
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					rng := rand.New(rand.NewSource(int64(w)))
+					for i := 0; i < ops; i++ {
+						id := fmt.Sprintf("w%d-%d", w, i)
+						vec := randomVecs(rng, 1, 8)[0]
+						var err error
+						switch i % 6 {
+						case 0:
+							err = db.Add(ctx, vector.Embedding{ID: id, Vec: vec, Meta: map[string]interface{}{"text": "added " + id}})
+						case 1:
+							err = db.AddN(ctx, []vector.Embedding{{ID: id, Vec: vec}, {ID: id + "b", Vec: vec}})
+						case 2:
+							_, err = db.Search(ctx, vec, 5)
+						case 3:
+							err = db.Delete(ctx, fmt.Sprint(rng.Intn(200)))
+						case 4:
+							_, err = db.TextSearch(ctx, "group3 doc", 5)
+						case 5:
+							if err = tenant.Add(ctx, vector.Embedding{ID: id, Vec: vec}); err == nil {
+								_, err = tenant.Search(ctx, vec, 3)
+							}
+						}
+						if err != nil {
+							errs <- err
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				t.Error(err)
+			}
+
+			// every write landed exactly once
+			if n, _ := tenant.Count(ctx); n != workers*ops/6 {
+				t.Errorf("tenant count = %d, want %d", n, workers*ops/6)
+			}
+			n, _ := db.Count(ctx)
+			res, err := db.Search(ctx, randomVecs(rand.New(rand.NewSource(9)), 1, 8)[0], n+10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != n {
+				t.Errorf("search returned %d of %d embeddings", len(res), n)
+			}
+		})
+	}
+}
+
+func BenchmarkSearchParallel(b *testing.B) {
+	db := NewInMemoryVectorDB(WithHNSW(HNSWConfig{Seed: 1}))
+	fill(b, db, rand.New(rand.NewSource(1)), 5000, 32)
+	queries := randomVecs(rand.New(rand.NewSource(2)), 100, 32)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			db.Search(context.Background(), queries[i%len(queries)], 10)
+			i++
+		}
+	})
+}
+
+func TestAddValidates(t *testing.T) {
+	ctx := context.Background()
+	db := NewInMemoryVectorDB()
+	bad := []vector.Embedding{
+		{ID: "", Vec: []float64{1}},
+		{ID: "a"},
+		{ID: "a", Vec: []float64{math.NaN()}},
+	}
+	for _, emb := range bad {
+		if err := db.Add(ctx, emb); err == nil {
+			t.Errorf("Add(%+v) accepted", emb)
+		}
+	}
+	if n, _ := db.Count(ctx); n != 0 {
+		t.Errorf("stored %d invalid embeddings", n)
+	}
+}
+
+func TestAddNBatchError(t *testing.T) {
+	ctx := context.Background()
+	db := NewInMemoryVectorDB()
+	err := db.AddN(ctx, []vector.Embedding{
+		{ID: "a", Vec: []float64{1, 0}},
+		{ID: "", Vec: []float64{1, 0}},
+		{ID: "b", Vec: []float64{0, 1}},
+		{ID: "c"},
+		{ID: "d", Vec: []float64{math.Inf(1), 0}},
+	})
+	var be *vector.BatchError
+	if !errors.As(err, &be) {
+		t.Fatalf("err = %v, want *vector.BatchError", err)
+	}
+	if len(be.Failed) != 3 || be.Failed[1] == nil || be.Failed[3] == nil || be.Failed[4] == nil {
+		t.Errorf("failed = %v, want items 1, 3 and 4", be.Failed)
+	}
+	if n, _ := db.Count(ctx); n != 2 {
+		t.Errorf("stored %d, want the 2 valid items", n)
+	}
+}
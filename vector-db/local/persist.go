@@ -0,0 +1,352 @@
+package local
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// On-disk mode keeps an append-only write-ahead log of every Add/Delete/Clear and
+// periodically compacts it into a snapshot. Each log line is
+// "<crc32 hex> <json record>\n"; recovery loads the snapshot, replays records newer
+// than it, and truncates a torn or corrupt tail left by a crash.
+
+const (
+	snapshotFile         = "snapshot.json"
+	walFile              = "wal.log"
+	defaultSnapshotEvery = 1000
+)
+
+type walOp string
+
+const (
+	opAdd    walOp = "add"
+	opDelete walOp = "delete"
+	opClear  walOp = "clear"
+)
+
+type walRecord struct {
+	Seq  uint64             `json:"seq"`
+	Op   walOp              `json:"op"`
+	NS   string             `json:"ns,omitempty"`
+	Embs []vector.Embedding `json:"embs,omitempty"`
+	ID   string             `json:"id,omitempty"`
+	IDs  []string           `json:"ids,omitempty"` // delete: more than one ID
+}
+
+type snapshot struct {
+	Seq        uint64                        `json:"seq"`  // last log record included
+	Embs       []vector.Embedding            `json:"embs"` // default namespace
+	Namespaces map[string][]vector.Embedding `json:"namespaces,omitempty"`
+}
+
+type walLog struct {
+	dir       string
+	f         *os.File
+	seq       uint64
+	pending   int // records since the last snapshot
+	snapEvery int
+	broken    error // set when a failed append could not be rolled back
+}
+
+// ErrClosed is returned by writes to a file-backed store after Close.
+var ErrClosed = errors.New("local_file vector db: closed")
+
+// WithSnapshotEvery compacts the log into a snapshot after n logged operations
+// (default 1000). Only used by NewFileVectorDB.
+func WithSnapshotEvery(n int) Option {
+	return func(db *InMemoryVectorDB) { db.snapEvery = n }
+}
+
+// NewFileVectorDB returns an InMemoryVectorDB persisted under dir, recovering any
+// state left by a previous process. Call Close when done.
+func NewFileVectorDB(dir string, opts ...Option) (*InMemoryVectorDB, error) {
+	if dir == "" {
+		return nil, errors.New("local_file vector db: path is required")
+	}
+	db := NewInMemoryVectorDB(opts...)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	w := &walLog{dir: dir, snapEvery: db.snapEvery}
+	if w.snapEvery <= 0 {
+		w.snapEvery = defaultSnapshotEvery
+	}
+	if err := db.recover(w); err != nil {
+		return nil, err
+	}
+	db.wal = w
+	return db, nil
+}
+
+// recover loads the snapshot, replays the log and opens it for appending.
+func (db *InMemoryVectorDB) recover(w *walLog) error {
+	b, err := os.ReadFile(filepath.Join(w.dir, snapshotFile))
+	switch {
+	case err == nil:
+		var snap snapshot
+		if err := json.Unmarshal(b, &snap); err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+		for _, emb := range snap.Embs {
+			db.put("", emb)
+		}
+		for ns, embs := range snap.Namespaces {
+			for _, emb := range embs {
+				db.put(ns, emb)
+			}
+		}
+		w.seq = snap.Seq
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(w.dir, walFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	good, err := db.replay(f, w)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// drop a torn or corrupt tail so new records follow the last good one
+	if err := f.Truncate(good); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+	w.f = f
+	return nil
+}
+
+// replay applies every intact record newer than the snapshot and returns the offset
+// just past the last intact record.
+func (db *InMemoryVectorDB) replay(f *os.File, w *walLog) (int64, error) {
+	r := bufio.NewReader(f)
+	var good int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return good, nil // partial last line, if any, is discarded
+		}
+		if err != nil {
+			return 0, err
+		}
+		rec, ok := decodeRecord(line)
+		if !ok {
+			return good, nil
+		}
+		good += int64(len(line))
+		if rec.Seq <= w.seq {
+			continue // already in the snapshot
+		}
+		db.applyRecord(rec)
+		w.seq = rec.Seq
+		w.pending++
+	}
+}
+
+func decodeRecord(line []byte) (walRecord, bool) {
+	var rec walRecord
+	sum, body, ok := bytes.Cut(bytes.TrimSuffix(line, []byte("\n")), []byte(" "))
+	if !ok {
+		return rec, false
+	}
+	want, err := strconv.ParseUint(string(sum), 16, 32)
+	if err != nil || crc32.ChecksumIEEE(body) != uint32(want) {
+		return rec, false
+	}
+	if err := json.Unmarshal(body, &rec); err != nil {
+		return rec, false
+	}
+	return rec, true
+}
+
+func (db *InMemoryVectorDB) applyRecord(rec walRecord) {
+	switch rec.Op {
+	case opAdd:
+		for _, emb := range rec.Embs {
+			db.put(rec.NS, emb)
+		}
+	case opDelete:
+		if rec.ID != "" {
+			db.remove(rec.NS, rec.ID)
+		}
+		for _, id := range rec.IDs {
+			db.remove(rec.NS, id)
+		}
+	case opClear:
+		db.reset(rec.NS)
+	}
+}
+
+// logRecord appends rec to the log and syncs it. It is a no-op for a purely
+// in-memory store. Callers hold db.mu.
+func (db *InMemoryVectorDB) logRecord(rec walRecord) error {
+	if db.closed {
+		return ErrClosed
+	}
+	w := db.wal
+	if w == nil {
+		return nil
+	}
+	if w.broken != nil {
+		return w.broken
+	}
+	rec.Seq = w.seq + 1
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(body), body)
+	off, err := w.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = w.f.WriteString(line); err == nil {
+		err = w.f.Sync()
+	}
+	if err != nil {
+		// a partial line would stop replay before every record appended after it
+		return w.rollback(off, err)
+	}
+	w.seq = rec.Seq
+	w.pending++
+	return nil
+}
+
+// rollback cuts the log back to off after a failed append. If that fails too, later
+// writes fail instead of following the garbage until a Snapshot rewrites the log.
+func (w *walLog) rollback(off int64, cause error) error {
+	err := w.f.Truncate(off)
+	if err == nil {
+		_, err = w.f.Seek(off, io.SeekStart)
+	}
+	if err != nil {
+		w.broken = fmt.Errorf("write-ahead log unusable after failed append: %w", errors.Join(cause, err))
+		return w.broken
+	}
+	return cause
+}
+
+// maybeCompact writes a snapshot once enough records have been logged.
+func (db *InMemoryVectorDB) maybeCompact() error {
+	if db.wal == nil || db.wal.pending < db.wal.snapEvery {
+		return nil
+	}
+	return db.compact()
+}
+
+// Snapshot compacts the log into a snapshot now. It is a no-op for a purely
+// in-memory store.
+func (db *InMemoryVectorDB) Snapshot() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return ErrClosed
+	}
+	if db.wal == nil {
+		return nil
+	}
+	return db.compact()
+}
+
+// compact writes the current state atomically (temp file, fsync, rename) and then
+// truncates the log. A crash in between is harmless: records already in the
+// snapshot are skipped by sequence number on recovery.
+func (db *InMemoryVectorDB) compact() error {
+	w := db.wal
+	snap := snapshot{Seq: w.seq, Embs: []vector.Embedding{}}
+	for ns, p := range db.parts {
+		embs := make([]vector.Embedding, 0, len(p.store))
+		for _, emb := range p.store {
+			embs = append(embs, emb)
+		}
+		if ns == "" {
+			snap.Embs = embs
+			continue
+		}
+		if snap.Namespaces == nil {
+			snap.Namespaces = make(map[string][]vector.Embedding)
+		}
+		snap.Namespaces[ns] = embs
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(w.dir, snapshotFile+".tmp")
+	if err := writeFileSync(tmp, b); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, snapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(w.dir); err != nil {
+		return err
+	}
+	if err := w.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	w.pending = 0
+	w.broken = nil
+	return w.f.Sync()
+}
+
+// Close flushes a final snapshot and closes the log. Reads still work afterwards;
+// writes fail with ErrClosed.
+func (db *InMemoryVectorDB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.wal == nil {
+		return nil
+	}
+	err := db.compact()
+	if cerr := db.wal.f.Close(); err == nil {
+		err = cerr
+	}
+	db.wal = nil
+	db.closed = true
+	return err
+}
+
+func writeFileSync(path string, b []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
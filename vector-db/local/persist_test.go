@@ -0,0 +1,243 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+func TestWALRollbackKeepsLaterRecords(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := NewFileVectorDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Add(ctx, vector.Embedding{ID: "a", Vec: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	// a write that failed halfway through the line
+	off, _ := db.wal.f.Seek(0, io.SeekCurrent)
+	db.wal.f.WriteString(`deadbeef {"seq":2,"op":"ad`)
+	cause := errors.New("disk full")
+	if err := db.wal.rollback(off, cause); err != cause {
+		t.Fatalf("rollback = %v", err)
+	}
+	if err := db.Add(ctx, vector.Embedding{ID: "b", Vec: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	db.wal.f.Close() // crash: no final snapshot
+
+	db, err = NewFileVectorDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, _ := db.Count(ctx); n != 2 {
+		t.Fatalf("recovered %d embeddings, want 2", n)
+	}
+}
+
+func TestWritesAfterClose(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewFileVectorDB(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	emb := vector.Embedding{ID: "a", Vec: []float64{1}}
+	for name, write := range map[string]func() error{
+		"Add":      func() error { return db.Add(ctx, emb) },
+		"AddN":     func() error { return db.AddN(ctx, []vector.Embedding{emb}) },
+		"Delete":   func() error { return db.Delete(ctx, "a") },
+		"Clear":    func() error { return db.Clear(ctx) },
+		"Snapshot": db.Snapshot,
+	} {
+		if err := write(); !errors.Is(err, ErrClosed) {
+			t.Errorf("%s after Close = %v, want ErrClosed", name, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
+
+func TestDeleteWhereIsLogged(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := NewFileVectorDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, doc := range []string{"a", "a", "b"} {
+		emb := vector.Embedding{ID: fmt.Sprint(i), Vec: []float64{1}, Meta: map[string]interface{}{"doc": doc}}
+		if err := db.Add(ctx, emb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := db.DeleteWhere(ctx, vector.Eq("doc", "a")); err != nil || n != 2 {
+		t.Fatalf("DeleteWhere = %d, %v", n, err)
+	}
+	db.wal.f.Close() // crash: no final snapshot
+
+	db, err = NewFileVectorDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if n, _ := db.Count(ctx); n != 1 {
+		t.Fatalf("recovered %d embeddings, want 1", n)
+	}
+}
+
+// crash drops db without the final snapshot Close would write.
+func crash(db *InMemoryVectorDB) {
+	db.wal.f.Close()
+}
+
+func ids(t *testing.T, db vector.VectorDB) map[string]bool {
+	t.Helper()
+	res, err := db.Search(context.Background(), []float64{1}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := map[string]bool{}
+	for _, r := range res {
+		out[r.ID] = true
+	}
+	return out
+}
+
+func TestRecoverSnapshotAndLog(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := NewFileVectorDB(dir, WithSnapshotEvery(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := db.Add(ctx, vector.Embedding{ID: id, Vec: []float64{1}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	// newer than the snapshot, only in the log
+	if err := db.Add(ctx, vector.Embedding{ID: "c", Vec: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithNamespace("t").Add(ctx, vector.Embedding{ID: "x", Vec: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	crash(db)
+
+	db, err = NewFileVectorDB(dir, WithSnapshotEvery(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(t, db); len(got) != 2 || !got["b"] || !got["c"] {
+		t.Errorf("recovered %v, want b and c", got)
+	}
+	if got := ids(t, db.WithNamespace("t")); len(got) != 1 || !got["x"] {
+		t.Errorf("recovered namespace %v, want x", got)
+	}
+	if db.wal.pending != 3 {
+		t.Errorf("%d records pending, want the 3 replayed", db.wal.pending)
+	}
+	// sequence numbers continue, so a record added now survives the next recovery
+	if err := db.Add(ctx, vector.Embedding{ID: "d", Vec: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	crash(db)
+	db, err = NewFileVectorDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if got := ids(t, db); len(got) != 3 || !got["d"] {
+		t.Errorf("second recovery %v, want b, c and d", got)
+	}
+}
+
+func TestRecoverTruncatesCorruptTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{"torn line", `1a2b3c4d {"seq":3,"op":"add","embs":[{"ID":"x"`},
+		{"bad checksum", "00000000 {\"seq\":3,\"op\":\"delete\",\"id\":\"a\"}\n"},
+		{"no checksum", "{\"seq\":3,\"op\":\"delete\",\"id\":\"a\"}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			db, err := NewFileVectorDB(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range []string{"a", "b"} {
+				if err := db.Add(ctx, vector.Embedding{ID: id, Vec: []float64{1}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			good, _ := db.wal.f.Seek(0, io.SeekCurrent)
+			db.wal.f.WriteString(tt.tail)
+			// an intact record after the corruption is not replayed either
+			body := `{"seq":4,"op":"clear"}`
+			fmt.Fprintf(db.wal.f, "%08x %s\n", crc32.ChecksumIEEE([]byte(body)), body)
+			crash(db)
+
+			db, err = NewFileVectorDB(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(t, db); len(got) != 2 || !got["a"] || !got["b"] {
+				t.Errorf("recovered %v, want a and b", got)
+			}
+			if fi, _ := db.wal.f.Stat(); fi.Size() != good {
+				t.Errorf("log is %d bytes, want it cut back to %d", fi.Size(), good)
+			}
+			if err := db.Add(ctx, vector.Embedding{ID: "c", Vec: []float64{1}}); err != nil {
+				t.Fatal(err)
+			}
+			crash(db)
+			db, err = NewFileVectorDB(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if got := ids(t, db); len(got) != 3 {
+				t.Errorf("after appending to the cut log: %v, want a, b and c", got)
+			}
+		})
+	}
+}
+
+func TestAddNBatchErrorCompacts(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewFileVectorDB(t.TempDir(), WithSnapshotEvery(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.AddN(ctx, []vector.Embedding{{ID: "a", Vec: []float64{1}}, {ID: "b"}})
+	var be *vector.BatchError
+	if !errors.As(err, &be) || len(be.Failed) != 1 {
+		t.Fatalf("err = %v, want a BatchError for item 1", err)
+	}
+	if db.wal.pending != 0 {
+		t.Errorf("%d records pending, want the log compacted", db.wal.pending)
+	}
+}
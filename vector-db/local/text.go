@@ -0,0 +1,50 @@
+package local
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// WithTextField sets the Meta key indexed for TextSearch (default "text").
+func WithTextField(key string) Option {
+	return func(db *InMemoryVectorDB) { db.textField = key }
+}
+
+// TextSearch ranks the namespace's embeddings by BM25 over their text field.
+func (db *InMemoryVectorDB) TextSearch(ctx context.Context, query string, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	o := vector.NewSearchOptions(opts...)
+	if err := o.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	if topK <= 0 {
+		return nil, nil
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	p := db.part(db.ns)
+	if p.text == nil {
+		return nil, nil
+	}
+	var out []vector.SearchResult
+	for id, score := range p.text.Scores(query) {
+		emb := p.store[id]
+		if !o.Filter.Match(emb.Meta) {
+			continue
+		}
+		out = append(out, vector.SearchResult{Embedding: emb, Score: score, Distance: -score, Metric: vector.BM25})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].ID < out[j].ID
+	})
+	if len(out) > topK {
+		out = out[:topK]
+	}
+	return out, nil
+}
+
+var _ vector.TextSearcher = &InMemoryVectorDB{}
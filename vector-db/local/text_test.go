@@ -0,0 +1,90 @@
+package local
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+func textDB(t *testing.T, docs map[string]string) *InMemoryVectorDB {
+	t.Helper()
+	db := NewInMemoryVectorDB()
+	for id, text := range docs {
+		meta := map[string]interface{}{"text": text, "lang": "en"}
+		if id == "d" {
+			meta["lang"] = "de"
+		}
+		if err := db.Add(context.Background(), vector.Embedding{ID: id, Vec: []float64{1}, Meta: meta}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestTextSearch(t *testing.T) {
+	db := textDB(t, map[string]string{
+		"a": "Error ERR_42 raised in parse_config",
+		"b": "the parser failed to parse the config file",
+		"c": "config config config",
+		"d": "config loader for the service with a much longer description",
+		"e": "unrelated text",
+	})
+	tests := []struct {
+		name  string
+		query string
+		opts  []vector.SearchOption
+		want  string
+	}{
+		// identifiers stay whole and match case-insensitively
+		{"error code", "err_42", nil, "a"},
+		{"identifier", "parse_config", nil, "a"},
+		{"word inside an identifier does not match", "raised parse", nil, "ab"},
+		// term frequency saturates; a short doc beats a long one with the same tf
+		{"frequency and length", "config", nil, "cbd"},
+		{"no match", "kubernetes", nil, ""},
+		{"filter", "config", []vector.SearchOption{vector.WithFilter(vector.Eq("lang", "de"))}, "d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := db.TextSearch(context.Background(), tt.query, 10, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			for i, r := range res {
+				got += r.ID
+				if r.Metric != vector.BM25 || r.Distance != -r.Score || r.Score <= 0 {
+					t.Errorf("%s: score %v distance %v metric %s", r.ID, r.Score, r.Distance, r.Metric)
+				}
+				if i > 0 && r.Score > res[i-1].Score {
+					t.Errorf("results not ordered by score")
+				}
+			}
+			if got != tt.want {
+				t.Errorf("order %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextSearchTiesAndUpdates(t *testing.T) {
+	ctx := context.Background()
+	db := textDB(t, map[string]string{"z": "same words", "y": "same words", "x": "other"})
+	res, _ := db.TextSearch(ctx, "same", 10)
+	if len(res) != 2 || res[0].ID != "y" || res[1].ID != "z" || res[0].Score != res[1].Score {
+		t.Errorf("tie = %v, want y then z with equal scores", res)
+	}
+	if res, _ := db.TextSearch(ctx, "same", 1); len(res) != 1 {
+		t.Errorf("topK 1 returned %d", len(res))
+	}
+	// replacing and deleting keep the index in sync
+	db.Add(ctx, vector.Embedding{ID: "y", Vec: []float64{1}, Meta: map[string]interface{}{"text": "different"}})
+	db.Delete(ctx, "z")
+	if res, _ := db.TextSearch(ctx, "same", 10); len(res) != 0 {
+		t.Errorf("stale postings: %v", res)
+	}
+	if res, _ := db.TextSearch(ctx, "different", 10); len(res) != 1 || res[0].ID != "y" {
+		t.Errorf("updated text not indexed: %v", res)
+	}
+}
@@ -0,0 +1,83 @@
+package vector
+
+import (
+	"fmt"
+	"math"
+)
+
+// Every backend ranks by the same distances so that one dataset orders identically
+// in memory and in Postgres. They mirror pgvector's operators: cosine is <=>
+// (1 - cosine similarity), L2 is <-> (Euclidean distance) and inner product is <#>
+// (the negated dot product).
+
+// DefaultMetric is used when Config.Metric is empty.
+const DefaultMetric = Cosine
+
+// Validate reports an unknown metric. The empty metric is valid and means
+// DefaultMetric.
+func (m Metric) Validate() error {
+	switch m {
+	case "", Cosine, InnerProduct, L2:
+		return nil
+	}
+	return fmt.Errorf("unknown metric %q", string(m))
+}
+
+// OrDefault returns m, or DefaultMetric when m is empty.
+func (m Metric) OrDefault() Metric {
+	if m == "" {
+		return DefaultMetric
+	}
+	return m
+}
+
+// Distance returns the distance between a and b under m; lower is closer.
+func (m Metric) Distance(a, b []float64) float64 {
+	switch m.OrDefault() {
+	case InnerProduct:
+		var dot float64
+		for i := range a {
+			dot += a[i] * b[i]
+		}
+		return -dot
+	case L2:
+		var sum float64
+		for i := range a {
+			d := a[i] - b[i]
+			sum += d * d
+		}
+		return math.Sqrt(sum)
+	default:
+		return 1 - CosineSimilarity(a, b)
+	}
+}
+
+// Score converts a distance under m to the higher-is-better SearchResult.Score:
+// cosine similarity, the dot product, or 1/(1+d) for L2.
+func (m Metric) Score(distance float64) float64 {
+	switch m.OrDefault() {
+	case InnerProduct:
+		return -distance
+	case L2:
+		return 1 / (1 + distance)
+	default:
+		return 1 - distance
+	}
+}
+
+// MaxDistance is the inverse of Score: results scoring at least minScore have a
+// distance no greater than the returned value. ok is false when every distance
+// qualifies.
+func (m Metric) MaxDistance(minScore float64) (max float64, ok bool) {
+	switch m.OrDefault() {
+	case InnerProduct:
+		return -minScore, true
+	case L2:
+		if minScore <= 0 {
+			return 0, false
+		}
+		return 1/minScore - 1, true
+	default:
+		return 1 - minScore, true
+	}
+}
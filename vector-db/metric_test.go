@@ -0,0 +1,119 @@
+package vector
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// pgvectorFixture holds what pgvector returns for q <op> doc, worked out by hand:
+// <=> is 1 - cosine similarity, <-> the Euclidean distance and <#> the negated dot
+// product.
+var pgvectorFixture = struct {
+	query []float64
+	docs  map[string][]float64
+	ops   map[Metric]map[string]float64
+}{
+	query: []float64{1, 2},
+	docs: map[string][]float64{
+		"a": {1, 2},
+		"b": {0, 3},
+		"c": {2, -1},
+		"d": {-1, -2},
+		"e": {3, 0},
+		"f": {4, 7},
+	},
+	ops: map[Metric]map[string]float64{
+		Cosine:       {"a": 0, "b": 1 - 6/(3*math.Sqrt(5)), "c": 1, "d": 2, "e": 1 - 3/(3*math.Sqrt(5)), "f": 1 - 18/math.Sqrt(325)},
+		L2:           {"a": 0, "b": math.Sqrt(2), "c": math.Sqrt(10), "d": math.Sqrt(20), "e": math.Sqrt(8), "f": math.Sqrt(34)},
+		InnerProduct: {"a": -5, "b": -6, "c": 0, "d": 5, "e": -3, "f": -18},
+	},
+}
+
+// pgOrder is the ORDER BY distance ASC order pgvector returns for m.
+func pgOrder(m Metric) []string {
+	dist := pgvectorFixture.ops[m]
+	ids := make([]string, 0, len(dist))
+	for id := range dist {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return dist[ids[i]] < dist[ids[j]] })
+	return ids
+}
+
+func TestDistanceMatchesPgvector(t *testing.T) {
+	for m, want := range pgvectorFixture.ops {
+		for id, d := range want {
+			if got := m.Distance(pgvectorFixture.query, pgvectorFixture.docs[id]); math.Abs(got-d) > 1e-9 {
+				t.Errorf("%s distance to %s = %v, pgvector gives %v", m, id, got, d)
+			}
+		}
+	}
+}
+
+func TestScoreRanksLikePgvector(t *testing.T) {
+	for m := range pgvectorFixture.ops {
+		want := pgOrder(m)
+		ids := append([]string(nil), want...)
+		score := func(id string) float64 {
+			return m.Score(m.Distance(pgvectorFixture.query, pgvectorFixture.docs[id]))
+		}
+		sort.Slice(ids, func(i, j int) bool { return score(ids[i]) > score(ids[j]) })
+		for i := range ids {
+			if ids[i] != want[i] {
+				t.Errorf("%s: score order %v, pgvector order %v", m, ids, want)
+				break
+			}
+		}
+	}
+}
+
+func TestScoreConventions(t *testing.T) {
+	q, docs := pgvectorFixture.query, pgvectorFixture.docs
+	// inner product: pgvector's <#> is the negated dot product, Score is the dot
+	// product itself so that higher is better
+	if d := InnerProduct.Distance(q, docs["f"]); d != -18 || InnerProduct.Score(d) != 18 {
+		t.Errorf("inner product distance %v score %v, want -18 and 18", d, InnerProduct.Score(d))
+	}
+	if s := InnerProduct.Score(InnerProduct.Distance(q, docs["d"])); s != -5 {
+		t.Errorf("opposite vector scores %v, want -5", s)
+	}
+	// cosine: Score is the cosine similarity
+	if s := Cosine.Score(Cosine.Distance(q, docs["d"])); math.Abs(s+1) > 1e-9 {
+		t.Errorf("cosine score of the opposite vector = %v, want -1", s)
+	}
+	// L2: Score is 1/(1+d), 1 for an identical vector
+	if s := L2.Score(L2.Distance(q, docs["a"])); s != 1 {
+		t.Errorf("L2 score of an identical vector = %v, want 1", s)
+	}
+	if Metric("").Distance(q, docs["b"]) != Cosine.Distance(q, docs["b"]) {
+		t.Error("empty metric is not cosine")
+	}
+}
+
+// TestMaxDistanceInvertsScore checks the MinScore filter: pgvector keeps rows with
+// distance <= MaxDistance(minScore), which must be exactly those scoring >= minScore.
+func TestMaxDistanceInvertsScore(t *testing.T) {
+	for m, dists := range pgvectorFixture.ops {
+		for _, d := range dists {
+			max, ok := m.MaxDistance(m.Score(d))
+			if !ok || math.Abs(max-d) > 1e-9 {
+				t.Errorf("%s: MaxDistance(Score(%v)) = %v, %v", m, d, max, ok)
+			}
+		}
+	}
+	if _, ok := L2.MaxDistance(0); ok {
+		t.Error("L2 MaxDistance(0) should accept every distance")
+	}
+}
+
+func TestValidateMetric(t *testing.T) {
+	for _, m := range []Metric{"", Cosine, L2, InnerProduct} {
+		if err := m.Validate(); err != nil {
+			t.Errorf("%q: %v", m, err)
+		}
+	}
+	if err := Metric("manhattan").Validate(); err == nil {
+		t.Error("unknown metric accepted")
+	}
+}
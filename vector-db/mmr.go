@@ -0,0 +1,82 @@
+package vector
+
+// MaxMarginalRelevance picks k results from candidates by Maximal Marginal Relevance
+// (Carbonell & Goldstein): each step takes the candidate maximising
+//
+//	lambda*sim(query, c) - (1-lambda)*max sim(c, already picked)
+//
+// with CosineSimilarity over Embedding.Vec. lambda 1 is plain relevance order,
+// lower values trade relevance for diversity; 0.5 to 0.7 suits near-duplicate
+// chunks. Picked results keep their original Score, in pick order.
+func MaxMarginalRelevance(query []float64, candidates []SearchResult, k int, lambda float64) []SearchResult {
+	rel := make([]float64, len(candidates))
+	for i, c := range candidates {
+		rel[i] = safeCosine(query, c.Vec)
+	}
+	return mmr(candidates, rel, k, lambda)
+}
+
+// MaxMarginalRelevanceByScore is MaxMarginalRelevance with each candidate's Score,
+// rescaled to [0, 1] over the candidates, as its relevance instead of its similarity
+// to a query vector. Use it after a reranker, whose scores are the better relevance
+// signal; with lambda 1 it keeps the reranked order.
+func MaxMarginalRelevanceByScore(candidates []SearchResult, k int, lambda float64) []SearchResult {
+	lo, hi := scoreRange(candidates)
+	rel := make([]float64, len(candidates))
+	for i, c := range candidates {
+		rel[i] = 1
+		if hi > lo {
+			rel[i] = (c.Score - lo) / (hi - lo)
+		}
+	}
+	return mmr(candidates, rel, k, lambda)
+}
+
+// mmr picks k candidates given their relevance.
+func mmr(candidates []SearchResult, rel []float64, k int, lambda float64) []SearchResult {
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+	if k <= 0 {
+		return nil
+	}
+	// redundancy[i] is the highest similarity of candidate i to any picked result
+	redundancy := make([]float64, len(candidates))
+	used := make([]bool, len(candidates))
+	out := make([]SearchResult, 0, k)
+	for len(out) < k {
+		best, bestScore := -1, 0.0
+		for i := range candidates {
+			if used[i] {
+				continue
+			}
+			score := lambda * rel[i]
+			if len(out) > 0 {
+				score -= (1 - lambda) * redundancy[i]
+			}
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		used[best] = true
+		out = append(out, candidates[best])
+		for i, c := range candidates {
+			if used[i] {
+				continue
+			}
+			if sim := safeCosine(c.Vec, candidates[best].Vec); sim > redundancy[i] || len(out) == 1 {
+				redundancy[i] = sim
+			}
+		}
+	}
+	return out
+}
+
+// safeCosine is CosineSimilarity, or 0 when the vectors differ in length (for
+// example a result returned without its vector).
+func safeCosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	return CosineSimilarity(a, b)
+}
@@ -0,0 +1,53 @@
+package vector
+
+import "testing"
+
+func ids(rs []SearchResult) string {
+	s := ""
+	for _, r := range rs {
+		s += r.ID
+	}
+	return s
+}
+
+// mmrCandidates is ordered as a reranker left it: b and d score highest, while c
+// and a are closer to the query vector [1, 0]. a and c are near-duplicates.
+func mmrCandidates() []SearchResult {
+	return []SearchResult{
+		{Embedding: Embedding{ID: "b", Vec: []float64{0.2, 1}}, Score: 9},
+		{Embedding: Embedding{ID: "d", Vec: []float64{0.5, 1}}, Score: 7},
+		{Embedding: Embedding{ID: "c", Vec: []float64{1, 0.01}}, Score: 3},
+		{Embedding: Embedding{ID: "a", Vec: []float64{1, 0}}, Score: 1},
+	}
+}
+
+func TestMaxMarginalRelevance(t *testing.T) {
+	q := []float64{1, 0}
+	if got := ids(MaxMarginalRelevance(q, mmrCandidates(), 2, 1)); got != "ac" {
+		t.Errorf("lambda 1 = %s, want cosine order ac", got)
+	}
+	// a and c are near-duplicates, so diversifying skips c
+	if got := ids(MaxMarginalRelevance(q, mmrCandidates(), 2, 0.5)); got[0] != 'a' || got[1] == 'c' {
+		t.Errorf("lambda 0.5 = %s, want a then not c", got)
+	}
+	if got := MaxMarginalRelevance(q, mmrCandidates(), 0, 0.5); got != nil {
+		t.Errorf("k 0 = %v", got)
+	}
+}
+
+func TestMaxMarginalRelevanceByScore(t *testing.T) {
+	if got := ids(MaxMarginalRelevanceByScore(mmrCandidates(), 4, 1)); got != "bdca" {
+		t.Errorf("lambda 1 = %s, want the reranked order bdca", got)
+	}
+	// d is close to b, so diversifying brings c forward
+	if got := ids(MaxMarginalRelevanceByScore(mmrCandidates(), 2, 0.5)); got != "bc" {
+		t.Errorf("lambda 0.5 = %s, want bc", got)
+	}
+	same := mmrCandidates()
+	for i := range same {
+		same[i].Score = 1
+	}
+	if got := ids(MaxMarginalRelevanceByScore(same, 1, 1)); got != "b" {
+		t.Errorf("equal scores = %s, want the first candidate", got)
+	}
+}
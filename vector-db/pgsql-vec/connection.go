@@ -2,45 +2,147 @@ package pgsqlvec
 
 import (
 	"context"
-	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
 )
 
+// Connect opens a pool to host:5432 with the default pool settings. Use
+// ConnectConfig for other ports, TLS or pool tuning.
 func Connect(rdsUsername, rdsPassword, rdsHostname, rdsDatabase string) (*pgxpool.Pool, error) {
-	// create the connection string
-	connection := fmt.Sprintf("postgres://%s:%s@%s:5432/%s", rdsUsername, rdsPassword,
-		rdsHostname, rdsDatabase)
-	pgxConfig, err := pgxpool.ParseConfig(connection)
+	return ConnectConfig(vector.Config{
+		User:     rdsUsername,
+		Password: rdsPassword,
+		Host:     rdsHostname,
+		Database: rdsDatabase,
+	})
+}
+
+// ConnectConfig opens a pool configured by PoolConfig.
+func ConnectConfig(cfg vector.Config) (*pgxpool.Pool, error) {
+	pgxConfig, err := PoolConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return pgxpool.ConnectConfig(context.Background(), pgxConfig)
+}
+
+// PoolConfig builds the pool configuration for cfg without connecting.
+//
+// Without a DSN the connection string is built from Host, Port, User, Password,
+// Database and the SSL fields, and unset pool settings and timeouts fall back to the
+// defaults below. With a DSN, pgx parses it as usual (so pool_max_conns and friends
+// work) and only the Config fields that are set override it.
+func PoolConfig(cfg vector.Config) (*pgxpool.Config, error) {
+	dsn := cfg.DSN
+	if dsn == "" {
+		cfg = withConnDefaults(cfg)
+		dsn = buildDSN(cfg)
+	}
+	pgxConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
 	}
 
 	// Configure connection pool settings
-	pgxConfig.MaxConns = 80
-	pgxConfig.MinConns = 10
-	pgxConfig.MaxConnLifetime = time.Hour * 2
-	pgxConfig.MaxConnIdleTime = time.Minute * 15
-	pgxConfig.HealthCheckPeriod = time.Minute * 2
+	if cfg.MaxConns > 0 {
+		pgxConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		pgxConfig.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		pgxConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		pgxConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
 
 	// Configure connection timeouts
-	pgxConfig.ConnConfig.ConnectTimeout = time.Second * 30
+	if cfg.ConnectTimeout > 0 {
+		pgxConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
 
 	runtimeParams := pgxConfig.ConnConfig.RuntimeParams
-	runtimeParams["application_name"] = "bandit"
-	runtimeParams["idle_in_transaction_session_timeout"] = "300000" // 5 minutes
-	runtimeParams["statement_timeout"] = "120000"                   // 2 minutes
-	runtimeParams["lock_timeout"] = "30000"                         // 30 seconds
-
-	runtimeParams["tcp_keepalives_idle"] = "300"
-	runtimeParams["tcp_keepalives_interval"] = "30"
-	runtimeParams["tcp_keepalives_count"] = "3"
-	// establish the connection
-	pool, err := pgxpool.ConnectConfig(context.Background(), pgxConfig)
-	if err != nil {
-		fmt.Println(err)
-		return nil, err
+	if cfg.ApplicationName != "" {
+		runtimeParams["application_name"] = cfg.ApplicationName
+	}
+	setMillis(runtimeParams, "idle_in_transaction_session_timeout", cfg.IdleInTransactionSessionTimeout)
+	setMillis(runtimeParams, "statement_timeout", cfg.StatementTimeout)
+	setMillis(runtimeParams, "lock_timeout", cfg.LockTimeout)
+	if cfg.DSN == "" {
+		pgxConfig.HealthCheckPeriod = time.Minute * 2
+		runtimeParams["tcp_keepalives_idle"] = "300"
+		runtimeParams["tcp_keepalives_interval"] = "30"
+		runtimeParams["tcp_keepalives_count"] = "3"
+	}
+	return pgxConfig, nil
+}
+
+// withConnDefaults fills the settings Connect has always used.
+func withConnDefaults(cfg vector.Config) vector.Config {
+	if cfg.Port == 0 {
+		cfg.Port = 5432
+	}
+	if cfg.ApplicationName == "" {
+		cfg.ApplicationName = "bandit"
+	}
+	if cfg.MaxConns == 0 {
+		cfg.MaxConns = 80
+	}
+	if cfg.MinConns == 0 {
+		cfg.MinConns = 10
+	}
+	if cfg.MaxConnLifetime == 0 {
+		cfg.MaxConnLifetime = time.Hour * 2
+	}
+	if cfg.MaxConnIdleTime == 0 {
+		cfg.MaxConnIdleTime = time.Minute * 15
+	}
+	if cfg.ConnectTimeout == 0 {
+		cfg.ConnectTimeout = time.Second * 30
+	}
+	if cfg.IdleInTransactionSessionTimeout == 0 {
+		cfg.IdleInTransactionSessionTimeout = time.Minute * 5
+	}
+	if cfg.StatementTimeout == 0 {
+		cfg.StatementTimeout = time.Minute * 2
+	}
+	if cfg.LockTimeout == 0 {
+		cfg.LockTimeout = time.Second * 30
+	}
+	return cfg
+}
+
+// buildDSN renders cfg as a postgres:// URL, escaping credentials and names.
+func buildDSN(cfg vector.Config) string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:   "/" + cfg.Database,
+	}
+	q := url.Values{}
+	for k, v := range map[string]string{
+		"sslmode":     cfg.SSLMode,
+		"sslrootcert": cfg.SSLRootCert,
+		"sslcert":     cfg.SSLCert,
+		"sslkey":      cfg.SSLKey,
+	} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func setMillis(params map[string]string, key string, d time.Duration) {
+	if d > 0 {
+		params[key] = strconv.FormatInt(d.Milliseconds(), 10)
 	}
-	return pool, nil
 }
@@ -0,0 +1,120 @@
+package pgsqlvec
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// filterSQL translates f into a boolean SQL expression over the JSONB meta column.
+// Field names and values are always bound as parameters, appended to args.
+func filterSQL(f *vector.Filter, args []interface{}) (string, []interface{}, error) {
+	if f == nil {
+		return "TRUE", args, nil
+	}
+	if err := f.Validate(); err != nil {
+		return "", nil, err
+	}
+	return buildFilter(f, args)
+}
+
+func buildFilter(f *vector.Filter, args []interface{}) (string, []interface{}, error) {
+	if f == nil {
+		// a nil operand matches everything, as in Filter.Match
+		return "TRUE", args, nil
+	}
+	param := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	switch f.Op {
+	case vector.OpEq:
+		cond, err := eqSQL(f.Field, f.Value, param)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("COALESCE(%s, FALSE)", cond), args, nil
+	case vector.OpIn:
+		if len(f.Values) == 0 {
+			return "FALSE", args, nil
+		}
+		parts := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			cond, err := eqSQL(f.Field, v, param)
+			if err != nil {
+				return "", nil, err
+			}
+			parts[i] = cond
+		}
+		return fmt.Sprintf("COALESCE((%s), FALSE)", strings.Join(parts, " OR ")), args, nil
+	case vector.OpGt, vector.OpGte, vector.OpLt, vector.OpLte:
+		op := map[vector.FilterOp]string{vector.OpGt: ">", vector.OpGte: ">=", vector.OpLt: "<", vector.OpLte: "<="}[f.Op]
+		field := param(f.Field)
+		v := vector.NormalizeFilterValue(f.Value)
+		if vector.IsNumeric(v) {
+			// CASE guards the cast from non-numeric values
+			return fmt.Sprintf("COALESCE(CASE WHEN jsonb_typeof(meta->%[1]s::text) = 'number' THEN (meta->>%[1]s::text)::float8 %[2]s %[3]s::float8 ELSE FALSE END, FALSE)",
+				field, op, param(v)), args, nil
+		}
+		return fmt.Sprintf(`COALESCE(jsonb_typeof(meta->%[1]s::text) = 'string' AND (meta->>%[1]s::text) COLLATE "C" %[2]s %[3]s::text, FALSE)`,
+			field, op, param(v)), args, nil
+	case vector.OpExists:
+		return fmt.Sprintf("COALESCE(meta ? %s::text, FALSE)", param(f.Field)), args, nil
+	case vector.OpAnd, vector.OpOr:
+		if len(f.Filters) == 0 {
+			if f.Op == vector.OpAnd {
+				return "TRUE", args, nil
+			}
+			return "FALSE", args, nil
+		}
+		parts := make([]string, len(f.Filters))
+		for i, c := range f.Filters {
+			var (
+				s   string
+				err error
+			)
+			s, args, err = buildFilter(c, args)
+			if err != nil {
+				return "", nil, err
+			}
+			parts[i] = "(" + s + ")"
+		}
+		return strings.Join(parts, " "+strings.ToUpper(string(f.Op))+" "), args, nil
+	case vector.OpNot:
+		s, args, err := buildFilter(f.Filters[0], args)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + s + ")", args, nil
+	}
+	return "", nil, fmt.Errorf("unknown filter op: %s", f.Op)
+}
+
+// eqSQL tests meta's field for equality with value. Scalars use containment, which
+// a GIN index on meta can serve; arrays and objects compare whole, since @> would
+// also match a superset.
+func eqSQL(field string, value interface{}, param func(interface{}) string) (string, error) {
+	v, err := json.Marshal(vector.NormalizeFilterValue(value))
+	if err != nil {
+		return "", fmt.Errorf("filter value for %s: %w", field, err)
+	}
+	if len(v) > 0 && (v[0] == '[' || v[0] == '{') {
+		return fmt.Sprintf("meta->%s::text = %s::jsonb", param(field), param(string(v))), nil
+	}
+	doc, err := containsDoc(field, value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("meta @> %s::jsonb", param(doc)), nil
+}
+
+// containsDoc renders {"field": value} for a JSONB containment test.
+func containsDoc(field string, value interface{}) (string, error) {
+	b, err := json.Marshal(map[string]interface{}{field: vector.NormalizeFilterValue(value)})
+	if err != nil {
+		return "", fmt.Errorf("filter value for %s: %w", field, err)
+	}
+	return string(b), nil
+}
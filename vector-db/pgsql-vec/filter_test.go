@@ -0,0 +1,47 @@
+package pgsqlvec
+
+import (
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+func TestFilterSQL(t *testing.T) {
+	color := vector.Eq("color", "red")
+	tests := []struct {
+		name   string
+		filter *vector.Filter
+		sql    string
+		args   []interface{}
+	}{
+		{"nil", nil, "TRUE", nil},
+		{"scalar eq", color, "COALESCE(meta @> $1::jsonb, FALSE)", []interface{}{`{"color":"red"}`}},
+		{"array eq", vector.Eq("tags", []string{"a", "b"}),
+			"COALESCE(meta->$1::text = $2::jsonb, FALSE)", []interface{}{"tags", `["a","b"]`}},
+		{"object in", vector.In("dims", map[string]int{"w": 1}, 3),
+			"COALESCE((meta->$1::text = $2::jsonb OR meta @> $3::jsonb), FALSE)", []interface{}{"dims", `{"w":1}`, `{"dims":3}`}},
+		// nil operands match everything, as in Filter.Match
+		{"and nil", vector.And(color, nil), "(COALESCE(meta @> $1::jsonb, FALSE)) AND (TRUE)", []interface{}{`{"color":"red"}`}},
+		{"or nil", vector.Or(color, nil), "(COALESCE(meta @> $1::jsonb, FALSE)) OR (TRUE)", []interface{}{`{"color":"red"}`}},
+		{"not nil", vector.Not(nil), "NOT (TRUE)", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := filterSQL(tt.filter, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.sql {
+				t.Errorf("sql = %s, want %s", sql, tt.sql)
+			}
+			if len(args) != len(tt.args) {
+				t.Fatalf("args = %v, want %v", args, tt.args)
+			}
+			for i := range args {
+				if args[i] != tt.args[i] {
+					t.Errorf("arg %d = %v, want %v", i+1, args[i], tt.args[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package pgsqlvec
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// Namespaces live in a "namespace" text column added by schema version 4; the
+// unique key becomes (namespace, id). Tables that have not been migrated keep
+// working in the default namespace only.
+
+const (
+	namespaceCol           = "namespace"
+	namespaceSchemaVersion = 4
+)
+
+// ErrNamespacesUnsupported is returned when a non-default namespace is used on a
+// table without the namespace column. Enable Config.AutoMigrate or call Migrate.
+var ErrNamespacesUnsupported = errors.New("pgsqlvec: table has no namespace column, run Migrate to add it")
+
+// WithNamespace returns a view of the same table scoped to namespace ns. It shares
+// the pool; closing the view does not close it.
+func (e *Entity) WithNamespace(ns string) vector.VectorDB {
+	v := *e
+	v.ns = ns
+	v.ownsPool = false
+	return &v
+}
+
+// scope returns the predicate restricting rows to e's namespace, binding the
+// namespace as a parameter appended to args.
+func (e *Entity) scope(args []interface{}) (string, []interface{}, error) {
+	if !e.namespaced {
+		if e.ns != "" {
+			return "", nil, ErrNamespacesUnsupported
+		}
+		return "TRUE", args, nil
+	}
+	args = append(args, e.ns)
+	return fmt.Sprintf("%s = $%d", namespaceCol, len(args)), args, nil
+}
+
+// conflictTarget is the unique key upserts resolve against.
+func (e *Entity) conflictTarget() string {
+	if e.namespaced {
+		return fmt.Sprintf("%s, %s", namespaceCol, e.idColname)
+	}
+	return fmt.Sprint(e.idColname)
+}
@@ -7,23 +7,76 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/shreetheja/ai-contextual-prompter/vector-db"
 )
 
 type Entity struct {
 	db        *pgxpool.Pool
+	ownsPool  bool
 	table     string
 	col       string
 	idColname interface{}
+	cfg       vector.Config
+	metric    vector.Metric
+
+	ns         string
+	namespaced bool // the table has the namespace column
+	fullText   bool // the table has the tsvector column
 }
 
+// NewEntity connects to Postgres. With cfg.AutoMigrate it also creates the table
+// and vector index if missing and applies pending schema migrations.
 func NewEntity(cfg vector.Config) (*Entity, error) {
-	pool, err := Connect(cfg.User, cfg.Password, cfg.Host, cfg.Database)
+	if err := cfg.Metric.Validate(); err != nil {
+		return nil, err
+	}
+	pool, err := ConnectConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	e, err := NewEntityWithPool(pool, cfg)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	e.ownsPool = true
+	return e, nil
+}
+
+// NewEntityWithPool is NewEntity on an existing pool, so the store shares the
+// application's connections. The connection fields of cfg are ignored and Close
+// leaves the pool open.
+func NewEntityWithPool(pool *pgxpool.Pool, cfg vector.Config) (*Entity, error) {
+	if err := cfg.Metric.Validate(); err != nil {
+		return nil, err
+	}
+	// the schema and every query read the id column from here
+	if cfg.IdColName == nil {
+		cfg.IdColName = defaultIDCol
+	}
+	e := &Entity{db: pool, table: cfg.Table, col: cfg.Col, idColname: cfg.IdColName, cfg: cfg, metric: cfg.Metric.OrDefault()}
+	ctx := context.Background()
+	if cfg.AutoMigrate {
+		if err := e.Migrate(ctx); err != nil {
+			return nil, err
+		}
+	}
+	v, err := e.SchemaVersion(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &Entity{db: pool, table: cfg.Table, col: cfg.Col, idColname: cfg.IdColName}, nil
+	e.namespaced = v >= namespaceSchemaVersion
+	e.fullText = v >= textSearchSchemaVersion
+	return e, nil
+}
+
+// Close closes the pool if NewEntity opened it.
+func (e *Entity) Close() {
+	if e.ownsPool {
+		e.db.Close()
+	}
 }
 
 func (e *Entity) Type(ctx context.Context) string {
@@ -31,8 +84,18 @@ func (e *Entity) Type(ctx context.Context) string {
 }
 
 func (e *Entity) Add(ctx context.Context, emb vector.Embedding) error {
+	if !e.namespaced && e.ns != "" {
+		return ErrNamespacesUnsupported
+	}
 	metaJson, _ := json.Marshal(emb.Meta)
 	vecStr := floatSliceToPgvector(emb.Vec)
+	if e.namespaced {
+		_, err := e.db.Exec(ctx,
+			fmt.Sprintf("INSERT INTO %s (%s, %s, %s, meta) VALUES ($4, $1, $2, $3) ON CONFLICT (%s) DO UPDATE SET %s = $2, meta = $3",
+				e.table, namespaceCol, e.idColname, e.col, e.conflictTarget(), e.col),
+			emb.ID, vecStr, metaJson, e.ns)
+		return err
+	}
 	_, err := e.db.Exec(ctx,
 		fmt.Sprintf("INSERT INTO %s (%s, %s, meta) VALUES ($1, $2, $3) ON CONFLICT (%s) DO UPDATE SET %s = $2, meta = $3",
 			e.table, e.idColname, e.col, e.idColname, e.col),
@@ -40,29 +103,139 @@ func (e *Entity) Add(ctx context.Context, emb vector.Embedding) error {
 	return err
 }
 
+// AddN bulk-upserts embeddings: rows are COPYed into a temporary staging table and
+// merged into the target table in one transaction. Invalid rows are reported in a
+// *vector.BatchError without blocking the rest; if the merge itself fails, every
+// row is reported failed and nothing is written. Duplicate IDs keep the last item.
 func (e *Entity) AddN(ctx context.Context, embs []vector.Embedding) error {
-	for _, emb := range embs {
-		if err := e.Add(ctx, emb); err != nil {
-			return err
+	if !e.namespaced && e.ns != "" {
+		return ErrNamespacesUnsupported
+	}
+	b := newBulkBatch(embs)
+	if len(b.order) > 0 {
+		if err := e.copyMerge(ctx, embs, b); err != nil {
+			b.mergeFailed(embs, err)
 		}
 	}
+	if len(b.failed) > 0 {
+		return &vector.BatchError{Failed: b.failed}
+	}
 	return nil
 }
 
-func (e *Entity) Search(ctx context.Context, query []float64, topK int) ([]vector.Embedding, error) {
+// bulkBatch is an AddN input after validation: one row per ID, the last item
+// winning, and the items rejected up front.
+type bulkBatch struct {
+	metas  [][]byte       // marshalled meta, nil for rejected items
+	order  []string       // IDs to write, in first-seen order
+	latest map[string]int // ID -> index of the item written for it
+	failed map[int]error
+}
+
+func newBulkBatch(embs []vector.Embedding) *bulkBatch {
+	b := &bulkBatch{metas: make([][]byte, len(embs)), latest: map[string]int{}, failed: map[int]error{}}
+	dims := 0
+	for i, emb := range embs {
+		if err := vector.ValidateEmbedding(emb, dims); err != nil {
+			b.failed[i] = err
+			continue
+		}
+		metaJson, err := json.Marshal(emb.Meta)
+		if err != nil {
+			b.failed[i] = fmt.Errorf("marshal meta: %w", err)
+			continue
+		}
+		dims = len(emb.Vec)
+		b.metas[i] = metaJson
+		if _, seen := b.latest[emb.ID]; !seen {
+			b.order = append(b.order, emb.ID)
+		}
+		b.latest[emb.ID] = i
+	}
+	return b
+}
+
+// mergeFailed reports every valid item as failed with err, including duplicates
+// superseded by a later item, since the merge wrote nothing.
+func (b *bulkBatch) mergeFailed(embs []vector.Embedding, err error) {
+	for i := range embs {
+		if b.metas[i] != nil {
+			b.failed[i] = err
+		}
+	}
+}
+
+func (e *Entity) copyMerge(ctx context.Context, embs []vector.Embedding, b *bulkBatch) error {
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	const stage = "vec_bulk_stage"
+	if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE TEMP TABLE %s (id text, vec text, meta text) ON COMMIT DROP", stage)); err != nil {
+		return fmt.Errorf("create staging table: %w", err)
+	}
+	rows := make([][]interface{}, len(b.order))
+	for n, id := range b.order {
+		i := b.latest[id]
+		rows[n] = []interface{}{id, floatSliceToPgvector(embs[i].Vec), string(b.metas[i])}
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{stage}, []string{"id", "vec", "meta"}, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("copy into staging table: %w", err)
+	}
+	cols, sel := fmt.Sprintf("%s, %s, meta", e.idColname, e.col), "id, vec::vector, meta::jsonb"
+	var args []interface{}
+	if e.namespaced {
+		cols, sel = namespaceCol+", "+cols, "$1, "+sel
+		args = append(args, e.ns)
+	}
+	merge := fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s
+		ON CONFLICT (%s) DO UPDATE SET %s = EXCLUDED.%s, meta = EXCLUDED.meta`,
+		e.table, cols, sel, stage, e.conflictTarget(), e.col, e.col)
+	if _, err := tx.Exec(ctx, merge, args...); err != nil {
+		return fmt.Errorf("merge staging table: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (e *Entity) Search(ctx context.Context, query []float64, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+	o := vector.NewSearchOptions(opts...)
 	vecStr := floatSliceToPgvector(query)
-	q := fmt.Sprintf(`SELECT %s, %s, meta FROM %s ORDER BY (%s <#> $1::vector) ASC LIMIT $2`, e.idColname, e.col, e.table, e.col)
-	rows, err := e.db.Query(ctx, q, vecStr, topK)
+	args := []interface{}{vecStr, topK}
+	where, args, err := filterSQL(o.Filter, args)
+	if err != nil {
+		return nil, err
+	}
+	scope, args, err := e.scope(args)
+	if err != nil {
+		return nil, err
+	}
+	where = fmt.Sprintf("%s AND (%s)", scope, where)
+	op := distanceOp(e.metric)
+	if o.MinScore != nil {
+		if max, ok := e.metric.MaxDistance(*o.MinScore); ok {
+			args = append(args, max)
+			where = fmt.Sprintf("(%s) AND (%s %s $1::vector) <= $%d", where, e.col, op, len(args))
+		}
+	}
+	q := fmt.Sprintf(`SELECT %s, %s, meta, (%s %s $1::vector) AS distance FROM %s WHERE %s ORDER BY distance ASC LIMIT $2`,
+		e.idColname, e.col, e.col, op, e.table, where)
+	rows, err := e.db.Query(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []vector.Embedding
+	var out []vector.SearchResult
 	for rows.Next() {
 		var id string
 		var vecStr string
 		var metaJson []byte
-		if err := rows.Scan(&id, &vecStr, &metaJson); err != nil {
+		var distance float64
+		if err := rows.Scan(&id, &vecStr, &metaJson, &distance); err != nil {
 			return nil, err
 		}
 		vec, err := parsePgvectorString(vecStr)
@@ -71,30 +244,90 @@ func (e *Entity) Search(ctx context.Context, query []float64, topK int) ([]vecto
 		}
 		var meta map[string]interface{}
 		json.Unmarshal(metaJson, &meta)
-		out = append(out, vector.Embedding{ID: id, Vec: vec, Meta: meta})
+		out = append(out, vector.SearchResult{
+			Embedding: vector.Embedding{ID: id, Vec: vec, Meta: meta},
+			Score:     e.metric.Score(distance),
+			Distance:  distance,
+			Metric:    e.metric,
+		})
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
 func (e *Entity) Count(ctx context.Context) (int, error) {
-	q := fmt.Sprintf("SELECT COUNT(*) FROM %s", e.table)
+	scope, args, err := e.scope(nil)
+	if err != nil {
+		return 0, err
+	}
+	q := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", e.table, scope)
 	var count int
-	err := e.db.QueryRow(ctx, q).Scan(&count)
+	err = e.db.QueryRow(ctx, q, args...).Scan(&count)
 	return count, err
 }
 
 func (e *Entity) Delete(ctx context.Context, id string) error {
-	q := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", e.table, e.idColname)
-	_, err := e.db.Exec(ctx, q, id)
+	scope, args, err := e.scope([]interface{}{id})
+	if err != nil {
+		return err
+	}
+	q := fmt.Sprintf("DELETE FROM %s WHERE %s = $1 AND %s", e.table, e.idColname, scope)
+	_, err = e.db.Exec(ctx, q, args...)
 	return err
 }
 
+// DeleteWhere removes every embedding in the entity's namespace whose meta matches f.
+func (e *Entity) DeleteWhere(ctx context.Context, f *vector.Filter) (int, error) {
+	where, args, err := filterSQL(f, nil)
+	if err != nil {
+		return 0, err
+	}
+	scope, args, err := e.scope(args)
+	if err != nil {
+		return 0, err
+	}
+	q := fmt.Sprintf("DELETE FROM %s WHERE %s AND (%s)", e.table, scope, where)
+	tag, err := e.db.Exec(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// Clear removes every embedding in the entity's namespace.
 func (e *Entity) Clear(ctx context.Context) error {
-	q := fmt.Sprintf("DELETE FROM %s", e.table)
-	_, err := e.db.Exec(ctx, q)
+	scope, args, err := e.scope(nil)
+	if err != nil {
+		return err
+	}
+	q := fmt.Sprintf("DELETE FROM %s WHERE %s", e.table, scope)
+	_, err = e.db.Exec(ctx, q, args...)
 	return err
 }
 
+// distanceOp returns the pgvector operator computing m's distance.
+func distanceOp(m vector.Metric) string {
+	switch m {
+	case vector.InnerProduct:
+		return "<#>"
+	case vector.L2:
+		return "<->"
+	default:
+		return "<=>"
+	}
+}
+
+// opsClass returns the index operator class that serves distanceOp(m).
+func opsClass(m vector.Metric) string {
+	switch m {
+	case vector.InnerProduct:
+		return "vector_ip_ops"
+	case vector.L2:
+		return "vector_l2_ops"
+	default:
+		return "vector_cosine_ops"
+	}
+}
+
 // floatSliceToPgvector converts a []float64 to a pgvector string literal: [0.1, 0.2, 0.3]
 func floatSliceToPgvector(vec []float64) string {
 	s := make([]string, len(vec))
@@ -0,0 +1,82 @@
+package pgsqlvec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// TestMetricOperators pins each metric to the pgvector operator whose result
+// vector.Metric.Distance reproduces, and to the index operator class serving it.
+func TestMetricOperators(t *testing.T) {
+	tests := []struct {
+		metric  vector.Metric
+		op      string
+		opClass string
+	}{
+		{"", "<=>", "vector_cosine_ops"},
+		{vector.Cosine, "<=>", "vector_cosine_ops"},
+		{vector.L2, "<->", "vector_l2_ops"},
+		{vector.InnerProduct, "<#>", "vector_ip_ops"},
+	}
+	for _, tt := range tests {
+		m := tt.metric.OrDefault()
+		if op := distanceOp(m); op != tt.op {
+			t.Errorf("%q: operator %s, want %s", tt.metric, op, tt.op)
+		}
+		if oc := opsClass(m); oc != tt.opClass {
+			t.Errorf("%q: ops class %s, want %s", tt.metric, oc, tt.opClass)
+		}
+		s, err := newSchemaSpec(vector.Config{Table: "docs", IdColName: "id", Col: "vec", Dimensions: 3, Metric: tt.metric})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.opsClass != tt.opClass {
+			t.Errorf("%q: schema index uses %s, want %s", tt.metric, s.opsClass, tt.opClass)
+		}
+	}
+}
+
+// TestInnerProductScoreSign checks that Search turns the <#> value pgvector returns
+// (the negated dot product) back into a positive score for aligned vectors.
+func TestInnerProductScoreSign(t *testing.T) {
+	e := &Entity{metric: vector.InnerProduct}
+	pgDistance := -18.0 // SELECT '[1,2]'::vector <#> '[4,7]'
+	if s := e.metric.Score(pgDistance); s != 18 {
+		t.Errorf("score = %v, want the dot product 18", s)
+	}
+	if max, _ := e.metric.MaxDistance(10); max != -10 {
+		t.Errorf("MinScore 10 filters on <#> <= %v, want -10", max)
+	}
+}
+
+func TestBulkBatch(t *testing.T) {
+	embs := []vector.Embedding{
+		{ID: "a", Vec: []float64{1, 0}},
+		{ID: "b", Vec: []float64{1}}, // dimensions differ from the first valid item
+		{ID: "c", Vec: []float64{0, 1}, Meta: map[string]interface{}{"bad": make(chan int)}},
+		{ID: "a", Vec: []float64{0, 1}}, // supersedes item 0
+		{ID: "d", Vec: []float64{1, 1}},
+		{ID: "", Vec: []float64{1, 1}},
+	}
+	b := newBulkBatch(embs)
+	if len(b.failed) != 3 || b.failed[1] == nil || b.failed[2] == nil || b.failed[5] == nil {
+		t.Errorf("rejected = %v, want items 1, 2 and 5", b.failed)
+	}
+	if len(b.order) != 2 || b.order[0] != "a" || b.order[1] != "d" || b.latest["a"] != 3 {
+		t.Errorf("rows = %v %v, want a (item 3) and d", b.order, b.latest)
+	}
+
+	// a failed merge wrote nothing: every valid item fails, superseded ones included
+	mergeErr := errors.New("merge staging table: deadlock")
+	b.mergeFailed(embs, mergeErr)
+	for _, i := range []int{0, 3, 4} {
+		if b.failed[i] != mergeErr {
+			t.Errorf("item %d: %v, want the merge error", i, b.failed[i])
+		}
+	}
+	if len(b.failed) != len(embs) || b.failed[1] == mergeErr {
+		t.Errorf("failed = %v, want validation errors kept", b.failed)
+	}
+}
@@ -0,0 +1,267 @@
+package pgsqlvec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// Schema changes are applied as numbered migrations recorded in
+// "<table>_schema_migrations". Migrate runs every pending one in a single
+// transaction under an advisory lock keyed on the table, so several processes can
+// start at once and the schema is either fully upgraded or left untouched.
+// Migrations are append-only: never edit one that has shipped, add a new version.
+
+const (
+	IndexHNSW    = "hnsw"
+	IndexIVFFlat = "ivfflat"
+	IndexNone    = "none"
+
+	defaultIDCol              = "id"
+	defaultIVFLists           = 100
+	defaultHNSWM              = 16
+	defaultHNSWEfConstruction = 64
+)
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// schemaSpec is everything the migrations need to render their DDL.
+type schemaSpec struct {
+	table     string // as configured, possibly schema-qualified
+	schema    string // "" or "<schema>." prefix of table
+	rel       string // unqualified table name, used to derive object names
+	idCol     string
+	col       string
+	dims      int
+	indexType string
+	opsClass  string
+	lists     int
+	m         int
+	efc       int
+	textCfg   string
+	textField string
+}
+
+func newSchemaSpec(cfg vector.Config) (schemaSpec, error) {
+	s := schemaSpec{
+		table:     cfg.Table,
+		idCol:     fmt.Sprint(cfg.IdColName),
+		col:       cfg.Col,
+		dims:      cfg.Dimensions,
+		indexType: strings.ToLower(cfg.IndexType),
+		opsClass:  cfg.IndexOpsClass,
+		lists:     cfg.IndexLists,
+		m:         cfg.HNSWM,
+		efc:       cfg.HNSWEfConstruction,
+		textCfg:   textSearchConfig(cfg),
+		textField: cfg.TextField,
+	}
+	if s.textField == "" {
+		s.textField = vector.DefaultTextField
+	}
+	if s.indexType == "" {
+		s.indexType = IndexHNSW
+	}
+	if s.opsClass == "" {
+		s.opsClass = opsClass(cfg.Metric.OrDefault())
+	}
+	if s.lists <= 0 {
+		s.lists = defaultIVFLists
+	}
+	if s.m <= 0 {
+		s.m = defaultHNSWM
+	}
+	if s.efc <= 0 {
+		s.efc = defaultHNSWEfConstruction
+	}
+	s.rel = s.table
+	if i := strings.LastIndex(s.table, "."); i >= 0 {
+		s.schema, s.rel = s.table[:i+1], s.table[i+1:]
+	}
+
+	if s.dims <= 0 {
+		return s, errors.New("pgsqlvec: Dimensions is required to create the table")
+	}
+	for _, id := range []string{s.rel, s.idCol, s.col, s.opsClass, s.textCfg} {
+		if !identRe.MatchString(id) {
+			return s, fmt.Errorf("pgsqlvec: %q is not a plain SQL identifier", id)
+		}
+	}
+	if s.schema != "" && !identRe.MatchString(strings.TrimSuffix(s.schema, ".")) {
+		return s, fmt.Errorf("pgsqlvec: %q is not a plain SQL identifier", s.schema)
+	}
+	switch s.indexType {
+	case IndexHNSW, IndexIVFFlat, IndexNone:
+	default:
+		return s, fmt.Errorf("pgsqlvec: unknown index type %q", cfg.IndexType)
+	}
+	return s, nil
+}
+
+func (s schemaSpec) migrationsTable() string {
+	return s.table + "_schema_migrations"
+}
+
+type migration struct {
+	version int
+	name    string
+	up      func(s schemaSpec) []string
+}
+
+// migrations lists every schema version in order. Statements use IF NOT EXISTS so a
+// table created by hand with the id, vector and meta columns can be adopted; version
+// 4 finds the id column's unique constraint or index by catalog lookup, whatever it
+// is named, and fails with an explanation if other objects depend on it.
+var migrations = []migration{
+	{1, "create table", func(s schemaSpec) []string {
+		return []string{
+			"CREATE EXTENSION IF NOT EXISTS vector",
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s text NOT NULL, %s vector(%d) NOT NULL, meta jsonb)",
+				s.table, s.idCol, s.col, s.dims),
+			// ON CONFLICT (id) in Add and AddN needs a unique index on the id column
+			fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s_%s_key ON %s (%s)", s.rel, s.idCol, s.table, s.idCol),
+		}
+	}},
+	{2, "vector index", func(s schemaSpec) []string {
+		switch s.indexType {
+		case IndexHNSW:
+			return []string{fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING hnsw (%s %s) WITH (m = %d, ef_construction = %d)",
+				s.rel, s.col, s.table, s.col, s.opsClass, s.m, s.efc)}
+		case IndexIVFFlat:
+			return []string{fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING ivfflat (%s %s) WITH (lists = %d)",
+				s.rel, s.col, s.table, s.col, s.opsClass, s.lists)}
+		}
+		return nil
+	}},
+	{3, "timestamps", func(s schemaSpec) []string {
+		fn := fmt.Sprintf("%s%s_touch_updated_at", s.schema, s.rel)
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS created_at timestamptz NOT NULL DEFAULT now(), ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now()", s.table),
+			fmt.Sprintf("CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$ BEGIN NEW.updated_at := now(); RETURN NEW; END $$", fn),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_touch_updated_at ON %s", s.rel, s.table),
+			fmt.Sprintf("CREATE TRIGGER %s_touch_updated_at BEFORE UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()", s.rel, s.table, fn),
+		}
+	}},
+	{4, "namespaces", func(s schemaSpec) []string {
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s text NOT NULL DEFAULT ''", s.table, namespaceCol),
+			fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s_%s_%s_key ON %s (%s, %s)", s.rel, namespaceCol, s.idCol, s.table, namespaceCol, s.idCol),
+			// IDs only need to be unique within a namespace now
+			dropIDUnique(s),
+		}
+	}},
+	{5, "full-text search", func(s schemaSpec) []string {
+		return []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s tsvector GENERATED ALWAYS AS (to_tsvector('%s'::regconfig, COALESCE(meta->>'%s', ''))) STORED",
+				s.table, tsvCol, s.textCfg, strings.ReplaceAll(s.textField, "'", "''")),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_%s_idx ON %s USING gin (%s)", s.rel, tsvCol, s.table, tsvCol),
+		}
+	}},
+}
+
+// dropIDUnique drops every unique constraint (primary key included) and unique index
+// on the id column alone. Migration 1 names its index <rel>_<id>_key, but that is
+// also the index behind a hand-written "id text UNIQUE", which can only be dropped
+// through its constraint, and a primary key is named <rel>_pkey.
+func dropIDUnique(s schemaSpec) string {
+	return fmt.Sprintf(`DO $mig$
+DECLARE
+	r record;
+BEGIN
+	FOR r IN
+		SELECT i.indexrelid::regclass AS idx, c.conname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = i.indkey[0]
+		LEFT JOIN pg_constraint c ON c.conrelid = i.indrelid AND c.conindid = i.indexrelid
+		WHERE i.indrelid = '%[1]s'::regclass AND i.indisunique AND i.indpred IS NULL AND i.indnatts = 1 AND a.attname = '%[2]s'
+	LOOP
+		BEGIN
+			IF r.conname IS NOT NULL THEN
+				EXECUTE format('ALTER TABLE %[1]s DROP CONSTRAINT %%I', r.conname);
+			ELSE
+				EXECUTE format('DROP INDEX %%s', r.idx);
+			END IF;
+		EXCEPTION WHEN dependent_objects_still_exist THEN
+			RAISE EXCEPTION 'pgsqlvec: cannot make %[2]s unique per namespace: %% on %[1]s is still in use', COALESCE('constraint ' || r.conname, 'index ' || r.idx::text)
+				USING HINT = 'Drop the foreign keys or other objects that depend on it, then migrate again.';
+		END;
+	END LOOP;
+END;
+$mig$`, s.table, strings.ToLower(s.idCol))
+}
+
+// LatestSchemaVersion is the schema version Migrate brings a table to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate creates the table and its indexes if missing and applies pending
+// migrations. NewEntity calls it when Config.AutoMigrate is set.
+func (e *Entity) Migrate(ctx context.Context) error {
+	s, err := newSchemaSpec(e.cfg)
+	if err != nil {
+		return err
+	}
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", "pgsqlvec:"+s.table); err != nil {
+		return fmt.Errorf("lock schema: %w", err)
+	}
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version int PRIMARY KEY, name text NOT NULL, applied_at timestamptz NOT NULL DEFAULT now())`, s.migrationsTable())); err != nil {
+		return fmt.Errorf("create migrations table: %w", err)
+	}
+	current, err := schemaVersion(ctx, tx, s)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		for _, stmt := range m.up(s) {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+			}
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", s.migrationsTable()),
+			m.version, m.name); err != nil {
+			return fmt.Errorf("record migration %d: %w", m.version, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// SchemaVersion returns the latest migration applied to the table, or 0 if Migrate
+// has never run against it.
+func (e *Entity) SchemaVersion(ctx context.Context) (int, error) {
+	var exists bool
+	table := e.table + "_schema_migrations"
+	if err := e.db.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+	var v int
+	err := e.db.QueryRow(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", table)).Scan(&v)
+	return v, err
+}
+
+func schemaVersion(ctx context.Context, tx pgx.Tx, s schemaSpec) (int, error) {
+	var v int
+	err := tx.QueryRow(ctx, fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", s.migrationsTable())).Scan(&v)
+	if err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return v, nil
+}
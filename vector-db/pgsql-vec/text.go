@@ -0,0 +1,86 @@
+package pgsqlvec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/shreetheja/ai-contextual-prompter/vector-db"
+)
+
+// Keyword search uses a generated tsvector column over meta->>TextField with a GIN
+// index, added by schema version 5, queried with websearch_to_tsquery and ranked by
+// ts_rank_cd.
+
+const (
+	tsvCol                  = "tsv"
+	textSearchSchemaVersion = 5
+	defaultTextSearchConfig = "english"
+)
+
+// ErrTextSearchUnsupported is returned by TextSearch on a table without the tsvector
+// column. Enable Config.AutoMigrate or call Migrate.
+var ErrTextSearchUnsupported = errors.New("pgsqlvec: table has no full-text column, run Migrate to add it")
+
+func textSearchConfig(cfg vector.Config) string {
+	if cfg.TextSearchConfig == "" {
+		return defaultTextSearchConfig
+	}
+	return cfg.TextSearchConfig
+}
+
+// TextSearch ranks the namespace's rows by full-text relevance to query. It must
+// use the same TextSearchConfig the column was generated with.
+func (e *Entity) TextSearch(ctx context.Context, query string, topK int, opts ...vector.SearchOption) ([]vector.SearchResult, error) {
+	if !e.fullText {
+		return nil, ErrTextSearchUnsupported
+	}
+	if topK <= 0 {
+		return nil, nil
+	}
+	o := vector.NewSearchOptions(opts...)
+	args := []interface{}{query, topK, textSearchConfig(e.cfg)}
+	where, args, err := filterSQL(o.Filter, args)
+	if err != nil {
+		return nil, err
+	}
+	scope, args, err := e.scope(args)
+	if err != nil {
+		return nil, err
+	}
+	q := fmt.Sprintf(`SELECT %s, %s, meta, ts_rank_cd(%s, q) AS rank
+		FROM %s, websearch_to_tsquery($3::regconfig, $1) q
+		WHERE %s @@ q AND %s AND (%s) ORDER BY rank DESC LIMIT $2`,
+		e.idColname, e.col, tsvCol, e.table, tsvCol, scope, where)
+	rows, err := e.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []vector.SearchResult
+	for rows.Next() {
+		var id string
+		var vecStr string
+		var metaJson []byte
+		var rank float64
+		if err := rows.Scan(&id, &vecStr, &metaJson, &rank); err != nil {
+			return nil, err
+		}
+		vec, err := parsePgvectorString(vecStr)
+		if err != nil {
+			return nil, err
+		}
+		var meta map[string]interface{}
+		json.Unmarshal(metaJson, &meta)
+		out = append(out, vector.SearchResult{
+			Embedding: vector.Embedding{ID: id, Vec: vec, Meta: meta},
+			Score:     rank,
+			Distance:  -rank,
+			Metric:    vector.TextRank,
+		})
+	}
+	return out, rows.Err()
+}
+
+var _ vector.TextSearcher = &Entity{}
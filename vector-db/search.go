@@ -0,0 +1,30 @@
+package vector
+
+// SearchOption tunes a VectorDB.Search call.
+type SearchOption func(*SearchOptions)
+
+// SearchOptions is the resolved set of search options. Backends build it with
+// NewSearchOptions.
+type SearchOptions struct {
+	Filter   *Filter
+	MinScore *float64
+}
+
+// WithFilter restricts the search to embeddings whose Meta matches f.
+func WithFilter(f *Filter) SearchOption {
+	return func(o *SearchOptions) { o.Filter = f }
+}
+
+// WithMinScore drops results whose Score is below min.
+func WithMinScore(min float64) SearchOption {
+	return func(o *SearchOptions) { o.MinScore = &min }
+}
+
+// NewSearchOptions applies opts in order.
+func NewSearchOptions(opts ...SearchOption) SearchOptions {
+	var o SearchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,113 @@
+package vector
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// BM25 defaults (Robertson et al.): k1 saturates term frequency, b normalises by
+// document length.
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// Tokenize lower-cases text and splits it on anything but letters, digits and
+// underscores, so identifiers like ERR_42 or parse_config stay whole.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// TextIndex is an inverted index that scores documents against a query with BM25.
+// It is not safe for concurrent use.
+type TextIndex struct {
+	k1, b    float64
+	postings map[string]map[string]int // term -> id -> term frequency
+	docs     map[string][]string       // id -> distinct terms, for removal
+	lens     map[string]int            // id -> token count
+	total    int
+}
+
+// NewTextIndex returns an empty index with BM25 parameters k1 and b.
+func NewTextIndex(k1, b float64) *TextIndex {
+	return &TextIndex{
+		k1:       k1,
+		b:        b,
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+		lens:     make(map[string]int),
+	}
+}
+
+// Add indexes text under id, replacing what id held before.
+func (t *TextIndex) Add(id, text string) {
+	t.Remove(id)
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return
+	}
+	tf := make(map[string]int)
+	for _, tok := range tokens {
+		tf[tok]++
+	}
+	terms := make([]string, 0, len(tf))
+	for term, n := range tf {
+		if t.postings[term] == nil {
+			t.postings[term] = make(map[string]int)
+		}
+		t.postings[term][id] = n
+		terms = append(terms, term)
+	}
+	t.docs[id] = terms
+	t.lens[id] = len(tokens)
+	t.total += len(tokens)
+}
+
+// Remove drops id from the index.
+func (t *TextIndex) Remove(id string) {
+	terms, ok := t.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range terms {
+		delete(t.postings[term], id)
+		if len(t.postings[term]) == 0 {
+			delete(t.postings, term)
+		}
+	}
+	t.total -= t.lens[id]
+	delete(t.docs, id)
+	delete(t.lens, id)
+}
+
+// Scores returns the BM25 score of every document containing a query term.
+func (t *TextIndex) Scores(query string) map[string]float64 {
+	n := float64(len(t.docs))
+	if n == 0 {
+		return nil
+	}
+	avg := float64(t.total) / n
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		post := t.postings[term]
+		df := float64(len(post))
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range post {
+			f := float64(tf)
+			norm := f + t.k1*(1-t.b+t.b*float64(t.lens[id])/avg)
+			scores[id] += idf * f * (t.k1 + 1) / norm
+		}
+	}
+	return scores
+}
@@ -2,6 +2,7 @@ package vector
 
 import (
 	"context"
+	"time"
 )
 
 // Embedding represents a vector and its associated metadata.
@@ -11,7 +12,37 @@ type Embedding struct {
 	Meta map[string]interface{}
 }
 
+// Metric is the distance function used to rank embeddings.
+type Metric string
+
+const (
+	Cosine       Metric = "cosine"
+	InnerProduct Metric = "inner_product"
+	L2           Metric = "l2"
+
+	// Keyword and fused rankings; not valid in Config.
+	BM25     Metric = "bm25"
+	TextRank Metric = "ts_rank"
+	Fused    Metric = "fused"
+)
+
+// SearchResult is an embedding returned by Search with its ranking values.
+// Score is higher-is-better for every metric (cosine similarity, dot product,
+// 1/(1+d) for L2);
+// Distance is the raw metric value the backend ordered by (lower is closer).
+type SearchResult struct {
+	Embedding
+	Score    float64
+	Distance float64
+	Metric   Metric
+}
+
 // VectorDB defines the interface for a vector database.
+//
+// Every store is divided into namespaces. The VectorDB returned by a constructor
+// works on the default namespace ""; WithNamespace returns a view on which Add,
+// Search, Count, Delete and Clear see only that namespace's embeddings. The same ID
+// may be used in different namespaces.
 type VectorDB interface {
 	// Returns the type of vector DB ( inmem or pg_sql)
 	Type(ctx context.Context) string
@@ -19,8 +50,12 @@ type VectorDB interface {
 	// Add stores an embedding in the database.
 	Add(ctx context.Context, emb Embedding) error
 
+	// AddN upserts many embeddings in one bulk write. If only some items fail it
+	// returns a *BatchError listing them; the rest are stored.
+	AddN(ctx context.Context, embs []Embedding) error
+
 	// Search returns the top K most similar embeddings to the query vector.
-	Search(ctx context.Context, query []float64, topK int) ([]Embedding, error)
+	Search(ctx context.Context, query []float64, topK int, opts ...SearchOption) ([]SearchResult, error)
 
 	// Count returns the number of embeddings stored.
 	Count(ctx context.Context) (int, error)
@@ -28,29 +63,71 @@ type VectorDB interface {
 	// Delete removes an embedding by ID.
 	Delete(ctx context.Context, id string) error
 
-	// Clear removes all embeddings.
+	// Clear removes all embeddings in the namespace.
 	Clear(ctx context.Context) error
+
+	// WithNamespace returns a view of the same store scoped to namespace ns.
+	WithNamespace(ns string) VectorDB
+}
+
+// FilterDeleter is implemented by stores that can delete every embedding in the
+// namespace whose Meta matches a filter. It returns the number removed; a nil
+// filter matches everything.
+type FilterDeleter interface {
+	DeleteWhere(ctx context.Context, f *Filter) (int, error)
 }
 
 // Vector DB type
 var (
-	IN_MEMORY = "in_mem"
-	PG_SQL    = "pg_sql"
+	IN_MEMORY  = "in_mem"
+	LOCAL_FILE = "local_file"
+	PG_SQL     = "pg_sql"
 )
 
 // Config for selecting and configuring a vector DB
 type Config struct {
-	Type string
+	Type      string
+	Metric    Metric // distance used to rank results, default Cosine
+	TextField string // Meta key indexed for keyword search, default "text"
 	// In-memory: no extra fields
+	// Local file:
+	Path          string // directory holding the snapshot and write-ahead log
+	SnapshotEvery int    // compact the log after this many operations, default 1000
 	// PGSQL:
 	Host      string
-	Port      int
+	Port      int // default 5432
 	User      string
 	Password  string
 	Database  string
 	Table     string
 	Col       string
 	IdColName interface{}
+	// PGSQL connection (see pgsqlvec.PoolConfig). DSN replaces Host, Port, User,
+	// Password, Database and the SSL fields; the settings below still apply on top.
+	DSN             string
+	SSLMode         string // disable, allow, prefer, require, verify-ca or verify-full
+	SSLRootCert     string // CA certificate file
+	SSLCert         string // client certificate file
+	SSLKey          string // client key file
+	ApplicationName string
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	ConnectTimeout  time.Duration
+	// server-side timeouts, sent as session parameters
+	StatementTimeout                time.Duration
+	LockTimeout                     time.Duration
+	IdleInTransactionSessionTimeout time.Duration
+	// PGSQL schema provisioning:
+	AutoMigrate        bool   // create the extension, table and index if missing and apply pending migrations
+	Dimensions         int    // vector column width, required by AutoMigrate
+	IndexType          string // "hnsw" (default), "ivfflat" or "none"
+	IndexOpsClass      string // operator class of the vector index, default matches Metric
+	IndexLists         int    // ivfflat lists, default 100
+	HNSWM              int    // hnsw m, default 16
+	HNSWEfConstruction int    // hnsw ef_construction, default 64
+	TextSearchConfig   string // Postgres text search configuration, default "english"
 }
 
 // Useful vector math helpers